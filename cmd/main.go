@@ -2,93 +2,174 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"memo-bot/internal/clock"
 	"memo-bot/internal/config"
 	"memo-bot/internal/discord"
+	"memo-bot/internal/health"
+	"memo-bot/internal/logging"
+	"memo-bot/internal/metrics"
+	"memo-bot/internal/ratelimit"
+	"memo-bot/internal/scheduler"
 	"memo-bot/internal/service"
+	"memo-bot/internal/storage"
 
 	"github.com/bwmarrin/discordgo"
-	_ "github.com/lib/pq"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", err)
 	}
+	setupLogging(cfg.Log)
+	slog.Info("Config loaded", "config", cfg)
 
-	db, err := sql.Open("postgres", cfg.Database.ConnectionString())
+	conn, queries, err := storage.Open(context.Background(), cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to open database", err, "driver", cfg.Database.Driver)
 	}
-	defer db.Close()
+	defer conn.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	// Schema changes are applied before anything touches the tables
+	if err := storage.Migrate(context.Background(), conn, cfg.Database.Driver); err != nil {
+		fatal("Failed to migrate database", err)
 	}
 
 	// Get local timezone
 	localLoc, err := time.LoadLocation("Local")
 	if err != nil {
-		log.Fatalf("Failed to load local timezone: %v", err)
+		fatal("Failed to load local timezone", err)
+	}
+
+	// Recurring memos are advanced in the configured timezone
+	appLoc, err := time.LoadLocation(cfg.App.Timezone)
+	if err != nil {
+		fatal("Failed to load configured timezone", err)
 	}
 
-	memoService := service.NewMemoService(db)
+	memoService := service.NewMemoService(queries, clock.System)
+	memoService.SetLimits(service.Limits{
+		MaxPendingPerUser:    cfg.Limits.MaxPendingPerUser,
+		MaxPendingPerChannel: cfg.Limits.MaxPendingPerChannel,
+	})
 
 	// Set up Discord client
-	discordClient, err := discord.NewClient(cfg.Discord.BotToken, memoService, cfg.App.Timezone)
+	discordClient, err := discord.NewClient(cfg.Discord.BotToken, memoService, cfg.App.Timezone, clock.System)
 	if err != nil {
-		log.Fatalf("Failed to create Discord client: %v", err)
+		fatal("Failed to create Discord client", err)
+	}
+	discordClient.SetCommandScope(discord.CommandScope{
+		GuildIDs:          cfg.Discord.GuildIDs,
+		UnregisterOnClose: cfg.Discord.UnregisterCommands,
+	})
+	if cfg.Limits.MemoRatePerMinute > 0 {
+		discordClient.SetMemoRateLimit(ratelimit.New(cfg.Limits.MemoRatePerMinute, cfg.Limits.MemoRateBurst, clock.System))
 	}
 
 	// Connect to Discord
 	if err := discordClient.Connect(); err != nil {
-		log.Fatalf("Failed to connect to Discord: %v", err)
+		fatal("Failed to connect to Discord", err)
 	}
 	defer discordClient.Close()
 
 	scanInterval, err := time.ParseDuration(cfg.App.ScanInterval)
 	if err != nil {
-		log.Fatalf("Failed to parse scan interval: %v", err)
+		fatal("Failed to parse scan interval", err)
 	}
 
-	ticker := time.NewTicker(scanInterval)
-	defer ticker.Stop()
+	claimLease, err := time.ParseDuration(cfg.App.ClaimLease)
+	if err != nil {
+		fatal("Failed to parse claim lease", err)
+	}
 
-	log.Printf("Backend started in timezone: %s", localLoc.String())
-	log.Printf("Checking for reminders every %v", scanInterval)
+	slog.Info("Backend started", "timezone", localLoc.String())
+	slog.Info("Delivering reminders on time, with a safety-net scan", "scan_interval", scanInterval)
+	slog.Info("Claiming reminders", "worker_id", cfg.App.WorkerID, "claim_lease", claimLease)
 
 	// Set up graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
-	// Check for missed reminders on startup
-	log.Printf("Performing initial scan for missed reminders...")
-	checkReminders(memoService, discordClient)
-	log.Printf("Initial scan completed")
-
-	running := true
-	for running {
-		select {
-		case <-ticker.C:
-			log.Printf("Scanning for reminders at %s...", time.Now().In(localLoc).Format("2006-01-02 15:04:05 MST"))
-			checkReminders(memoService, discordClient)
-			log.Printf("Scan completed at %s", time.Now().In(localLoc).Format("2006-01-02 15:04:05 MST"))
-		case <-stop:
-			log.Println("Shutting down gracefully...")
-			running = false
+	// The scheduler wakes up exactly at the next reminder; memo changes made
+	// through the service keep its in-memory schedule up to date
+	worker := &reminderWorker{
+		id:      cfg.App.WorkerID,
+		lease:   claimLease,
+		loc:     appLoc,
+		service: memoService,
+		discord: discordClient,
+		clock:   clock.System,
+	}
+	sched := scheduler.New(memoService, worker.checkReminders, scanInterval, clock.System)
+	memoService.SetScheduleNotifier(sched)
+
+	// Health checks and metrics are optional, enabled by HTTP_ADDR
+	var healthServer *health.Server
+	if cfg.App.HTTPAddr != "" {
+		healthServer = health.NewServer(cfg.App.HTTPAddr, map[string]health.Check{
+			"database": conn.PingContext,
+			"discord": func(ctx context.Context) error {
+				if !discordClient.IsConnected() {
+					return fmt.Errorf("not connected to the gateway")
+				}
+				return nil
+			},
+		})
+		healthServer.Start()
+		slog.Info("Serving /healthz, /readyz and /metrics", "addr", cfg.App.HTTPAddr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sched.Run(ctx)
+		close(done)
+	}()
+
+	<-stop
+	slog.Info("Shutting down gracefully")
+	cancel()
+	<-done
+
+	if healthServer != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := healthServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Error stopping health server", logging.KeyError, err)
 		}
 	}
 }
 
+// setupLogging installs the configured logger as the default, which also
+// routes the standard log package (used by discordgo) through it
+func setupLogging(cfg config.LogConfig) {
+	logger, err := logging.New(os.Stderr, cfg.Level, cfg.Format)
+	if err != nil {
+		fatal("Invalid logging config", err)
+	}
+	slog.SetDefault(logger)
+}
+
+// fatal logs an error and exits
+func fatal(msg string, err error, args ...any) {
+	slog.Error(msg, append(args, logging.KeyError, err)...)
+	os.Exit(1)
+}
+
 func handleSetChannel(s *discordgo.Session, m *discordgo.MessageCreate, service *service.MemoService) {
 	parts := strings.Fields(m.Content)
 	if len(parts) != 2 {
@@ -102,7 +183,7 @@ func handleSetChannel(s *discordgo.Session, m *discordgo.MessageCreate, service
 	// Update the user's Discord channel
 	err := service.UpdateUserDiscordChannel(ctx, userID, m.ChannelID)
 	if err != nil {
-		log.Printf("Error updating user's Discord channel: %v", err)
+		slog.Error("Error updating user's Discord channel", logging.KeyUserID, userID, logging.KeyError, err)
 		s.ChannelMessageSend(m.ChannelID, "❌ Failed to set channel. Make sure your user ID is correct.")
 		return
 	}
@@ -120,28 +201,91 @@ Note: The user_id is the same ID you use in the CLI application.`
 	s.ChannelMessageSend(m.ChannelID, helpText)
 }
 
-func checkReminders(service *service.MemoService, discordClient *discord.Client) {
-	ctx := context.Background()
-	now := time.Now().UTC()
+// claimBatchSize is how many due reminders are claimed per round trip. Batches
+// are kept small so they are sent well within CLAIM_LEASE, and each claim is
+// renewed right before its memo is sent.
+const claimBatchSize = 10
 
-	reminders, err := service.GetPendingReminders(ctx, now)
-	if err != nil {
-		log.Printf("Error getting pending reminders: %v", err)
-		return
-	}
+// reminderWorker claims due reminders and delivers them. Claims are leases, so
+// several bot instances can share one database without duplicating reminders.
+type reminderWorker struct {
+	id      string
+	lease   time.Duration
+	loc     *time.Location
+	service *service.MemoService
+	discord *discord.Client
+	clock   clock.Clock
+}
 
-	if len(reminders) > 0 {
-		log.Printf("Found %d reminder(s) to process", len(reminders))
-	}
+// checkReminders runs one scan cycle. Its log lines share a correlation ID.
+func (w *reminderWorker) checkReminders(ctx context.Context) {
+	logger := slog.With(logging.KeyCorrelationID, logging.NewID())
+	ctx = logging.WithLogger(ctx, logger)
+
+	start := w.clock.Now()
+	defer func() {
+		metrics.ScanDuration.Observe(w.clock.Now().Sub(start).Seconds())
+	}()
+
+	for {
+		now := w.clock.Now().UTC()
+
+		reminders, err := w.service.ClaimDueReminders(ctx, w.id, now, w.lease, claimBatchSize)
+		if err != nil {
+			logger.Error("Error claiming pending reminders", logging.KeyError, err)
+			return
+		}
+
+		if len(reminders) > 0 {
+			logger.Info("Claimed reminders to process", "count", len(reminders))
+		}
+
+		for _, reminder := range reminders {
+			memoLogger := logger.With(
+				logging.KeyMemoID, reminder.ID,
+				logging.KeyUserID, reminder.DiscordUserID,
+				logging.KeyGuildID, reminder.GuildID.String,
+			)
+			memoCtx := logging.WithLogger(ctx, memoLogger)
+
+			// Sending the batch may have outlived the lease
+			claimed, err := w.service.RenewClaim(memoCtx, reminder, w.clock.Now().UTC(), w.lease)
+			if err != nil {
+				memoLogger.Error("Error renewing claim", logging.KeyError, err)
+				continue
+			}
+			if !claimed {
+				memoLogger.Warn("Lost claim on memo before sending it, skipping")
+				continue
+			}
+
+			if err := w.discord.SendReminder(memoCtx, reminder); err != nil {
+				permanent := discord.IsPermanentError(err)
+				metrics.RemindersFailed.WithLabelValues(strconv.FormatBool(permanent)).Inc()
+				failed, recordErr := w.service.RecordDeliveryFailure(memoCtx, reminder, err, permanent, w.clock.Now().UTC())
+				if recordErr != nil {
+					memoLogger.Error("Error recording delivery failure", logging.KeyError, recordErr)
+					continue
+				}
+				if failed {
+					memoLogger.Error("Giving up on memo", "attempts", reminder.Attempts+1, logging.KeyError, err)
+				} else {
+					memoLogger.Warn("Error sending memo, will retry", "attempt", reminder.Attempts+1, logging.KeyError, err)
+				}
+				continue
+			}
+			metrics.RemindersSent.Inc()
+			metrics.DeliveryLag.Observe(w.clock.Now().Sub(reminder.RemindAt).Seconds())
+
+			memoLogger.Debug("Delivered reminder", "lag", now.Sub(reminder.RemindAt))
 
-	for _, reminder := range reminders {
-		if err := discordClient.SendReminder(reminder); err != nil {
-			log.Printf("Error sending reminder: %v", err)
-			continue
+			if err := w.service.CompleteReminder(memoCtx, reminder, now, w.loc); err != nil {
+				memoLogger.Error("Error completing memo", logging.KeyError, err)
+			}
 		}
 
-		if err := service.MarkMemoAsSent(ctx, reminder.ID); err != nil {
-			log.Printf("Error marking memo as sent: %v", err)
+		if len(reminders) < claimBatchSize {
+			return
 		}
 	}
 }
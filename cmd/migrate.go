@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"memo-bot/internal/config"
+	"memo-bot/internal/migrate"
+	"memo-bot/internal/storage"
+)
+
+const migrateUsage = "Usage: memo-bot migrate <up|down|status>"
+
+// runMigrate implements the `migrate` subcommand. Only the database settings
+// are needed, so it works without a Discord token.
+func runMigrate(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadDatabaseConfig()
+	if err != nil {
+		fatal("Failed to load config", err)
+	}
+	setupLogging(config.LoadLogConfig())
+
+	ctx := context.Background()
+	conn, _, err := storage.Open(ctx, *cfg)
+	if err != nil {
+		fatal("Failed to open database", err, "driver", cfg.Driver)
+	}
+	defer conn.Close()
+
+	runner, err := migrate.New(conn, cfg.Driver)
+	if err != nil {
+		fatal("Failed to load migrations", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := runner.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fatal("Failed to migrate database", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+	case "down":
+		m, err := runner.Down(ctx)
+		if err != nil {
+			fatal("Failed to roll back migration", err)
+		}
+		if m == nil {
+			fmt.Println("No migrations to roll back")
+			return
+		}
+		fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			fatal("Failed to read migration status", err)
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.Applied {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%-28s %s\n", st.Version, st.Name, state)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,136 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to. Timers and tickers fire during
+// Advance and Set, in deadline order, with the fake time they were due at.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// NewFake returns a fake clock stopped at now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the clock forward by d
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to t, firing every timer and ticker due by then
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for {
+		w := f.nextDue(t)
+		if w == nil {
+			break
+		}
+		f.now = w.at
+		w.fire()
+	}
+	f.now = t
+}
+
+// Waiters returns how many timers and tickers are active, so tests can wait
+// for the code under test to start waiting before advancing the clock
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.add(d, 0)
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.add(d, d)}
+}
+
+func (f *Fake) add(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{clock: f, at: f.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	if d <= 0 {
+		// Like time.NewTimer, a non-positive duration fires immediately
+		w.c <- f.now
+		if period == 0 {
+			return w
+		}
+		w.at = f.now.Add(period)
+	}
+	f.waiters = append(f.waiters, w)
+	return w
+}
+
+// nextDue removes and returns the earliest waiter due at or before t. The
+// caller holds f.mu.
+func (f *Fake) nextDue(t time.Time) *fakeWaiter {
+	sort.SliceStable(f.waiters, func(i, j int) bool { return f.waiters[i].at.Before(f.waiters[j].at) })
+	if len(f.waiters) == 0 || f.waiters[0].at.After(t) {
+		return nil
+	}
+	w := f.waiters[0]
+	f.waiters = f.waiters[1:]
+	return w
+}
+
+func (f *Fake) remove(w *fakeWaiter) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeWaiter struct {
+	clock  *Fake
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+// fire delivers the tick without blocking, dropping it if the last one wasn't
+// read, like the runtime does. Tickers are re-armed. The caller holds clock.mu.
+func (w *fakeWaiter) fire() {
+	select {
+	case w.c <- w.at:
+	default:
+	}
+	if w.period > 0 {
+		w.at = w.at.Add(w.period)
+		w.clock.waiters = append(w.clock.waiters, w)
+	}
+}
+
+func (w *fakeWaiter) C() <-chan time.Time { return w.c }
+
+func (w *fakeWaiter) Stop() bool {
+	return w.clock.remove(w)
+}
+
+type fakeTicker struct{ *fakeWaiter }
+
+func (t fakeTicker) Stop() { t.fakeWaiter.Stop() }
@@ -2,9 +2,10 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,9 +15,15 @@ type Config struct {
 	Database DatabaseConfig
 	App      AppConfig
 	Discord  DiscordConfig
+	Log      LogConfig
+	Limits   LimitsConfig
 }
 
 type DatabaseConfig struct {
+	// Driver is "postgres" (default) or "sqlite"
+	Driver string
+	// Path is the database file used by the sqlite driver
+	Path     string
 	Host     string
 	Port     int
 	User     string
@@ -28,56 +35,75 @@ type DatabaseConfig struct {
 type AppConfig struct {
 	ScanInterval string
 	Timezone     string
+	// WorkerID identifies this instance in reminder claims
+	WorkerID string
+	// ClaimLease is how long a claimed reminder stays reserved for this instance
+	ClaimLease string
+	// HTTPAddr is the listen address of the health and metrics server; empty disables it
+	HTTPAddr string
 }
 
 type DiscordConfig struct {
 	BotToken string
+	// GuildIDs registers slash commands in these guilds instead of globally
+	GuildIDs []string
+	// UnregisterCommands removes the slash commands on shutdown
+	UnregisterCommands bool
+}
+
+// LimitsConfig protects the bot from users flooding it with memos. Zero
+// disables a limit.
+type LimitsConfig struct {
+	// MaxPendingPerUser caps a user's pending memos across all servers
+	MaxPendingPerUser int
+	// MaxPendingPerChannel caps the pending memos posted in one channel
+	MaxPendingPerChannel int
+	// MemoRatePerMinute is how many memo creations a user regains per minute
+	MemoRatePerMinute int
+	// MemoRateBurst is how many memos a user can create in a row
+	MemoRateBurst int
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error
+	Level string
+	// Format is text or json
+	Format string
 }
 
 func LoadConfig() (*Config, error) {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: .env file not found or could not be loaded: %v", err)
-	} else {
-		log.Println("Successfully loaded .env file")
-	}
+	loadEnvFile()
 
 	config := &Config{
-		Database: DatabaseConfig{
-			Host:     getEnvOrDefault("DB_HOST", "localhost"),
-			Port:     getEnvAsIntOrDefault("DB_PORT", 5432),
-			User:     getEnvOrDefault("DB_USER", "postgres"),
-			Password: os.Getenv("DB_PASSWORD"),
-			DBName:   getEnvOrDefault("DB_NAME", "memodb"),
-			SSLMode:  getEnvOrDefault("DB_SSLMODE", "disable"),
-		},
+		Database: databaseConfigFromEnv(),
 		App: AppConfig{
 			ScanInterval: getEnvOrDefault("SCAN_INTERVAL", "60s"),
 			Timezone:     getEnvOrDefault("TIMEZONE", "UTC"),
+			WorkerID:     getEnvOrDefault("WORKER_ID", defaultWorkerID()),
+			ClaimLease:   getEnvOrDefault("CLAIM_LEASE", "2m"),
+			HTTPAddr:     os.Getenv("HTTP_ADDR"),
 		},
 		Discord: DiscordConfig{
-			BotToken: os.Getenv("DISCORD_BOT_TOKEN"),
+			BotToken:           os.Getenv("DISCORD_BOT_TOKEN"),
+			GuildIDs:           getEnvAsList("DISCORD_GUILD_IDS"),
+			UnregisterCommands: getEnvAsBoolOrDefault("DISCORD_UNREGISTER_COMMANDS", false),
+		},
+		Log: logConfigFromEnv(),
+		Limits: LimitsConfig{
+			MaxPendingPerUser:    getEnvAsIntOrDefault("MAX_PENDING_PER_USER", 100),
+			MaxPendingPerChannel: getEnvAsIntOrDefault("MAX_PENDING_PER_CHANNEL", 500),
+			MemoRatePerMinute:    getEnvAsIntOrDefault("MEMO_RATE_PER_MINUTE", 10),
+			MemoRateBurst:        getEnvAsIntOrDefault("MEMO_RATE_BURST", 5),
 		},
 	}
 
-	// Debug: Print all environment variables
-	log.Printf("Environment variables:")
-	log.Printf("DB_HOST: %s", config.Database.Host)
-	log.Printf("DB_PORT: %d", config.Database.Port)
-	log.Printf("DB_USER: %s", config.Database.User)
-	log.Printf("DB_NAME: %s", config.Database.DBName)
-	log.Printf("DB_SSLMODE: %s", config.Database.SSLMode)
-	log.Printf("SCAN_INTERVAL: %s", config.App.ScanInterval)
-	log.Printf("TIMEZONE: %s", config.App.Timezone)
-	log.Printf("DISCORD_BOT_TOKEN length: %d", len(config.Discord.BotToken))
-
 	// Validate required fields
 	if config.Discord.BotToken == "" {
 		return nil, fmt.Errorf("DISCORD_BOT_TOKEN is required")
 	}
 
-	if config.Database.Password == "" {
-		return nil, fmt.Errorf("DB_PASSWORD is required")
+	if err := config.Database.validate(); err != nil {
+		return nil, err
 	}
 
 	// Parse scan interval duration
@@ -87,17 +113,139 @@ func LoadConfig() (*Config, error) {
 	}
 	config.App.ScanInterval = scanInterval.String()
 
-	// Debug logging (without exposing sensitive data)
-	log.Printf("Config loaded successfully")
+	claimLease, err := time.ParseDuration(config.App.ClaimLease)
+	if err != nil || claimLease <= 0 {
+		return nil, fmt.Errorf("invalid claim lease %q, use a positive duration like 2m", config.App.ClaimLease)
+	}
+	config.App.ClaimLease = claimLease.String()
+
+	if err := config.Limits.validate(); err != nil {
+		return nil, err
+	}
 
 	return config, nil
 }
 
+// LogValue lists the settings for the startup log, without secrets
+func (c *Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("db_driver", c.Database.Driver),
+		slog.String("db_path", c.Database.Path),
+		slog.String("db_host", c.Database.Host),
+		slog.Int("db_port", c.Database.Port),
+		slog.String("db_user", c.Database.User),
+		slog.String("db_name", c.Database.DBName),
+		slog.String("db_sslmode", c.Database.SSLMode),
+		slog.String("scan_interval", c.App.ScanInterval),
+		slog.String("timezone", c.App.Timezone),
+		slog.String("worker_id", c.App.WorkerID),
+		slog.String("claim_lease", c.App.ClaimLease),
+		slog.String("http_addr", c.App.HTTPAddr),
+		slog.Int("max_pending_per_user", c.Limits.MaxPendingPerUser),
+		slog.Int("max_pending_per_channel", c.Limits.MaxPendingPerChannel),
+		slog.Int("memo_rate_per_minute", c.Limits.MemoRatePerMinute),
+		slog.Int("memo_rate_burst", c.Limits.MemoRateBurst),
+		slog.String("log_level", c.Log.Level),
+		slog.String("log_format", c.Log.Format),
+		slog.Int("discord_bot_token_length", len(c.Discord.BotToken)),
+		slog.Any("discord_guild_ids", c.Discord.GuildIDs),
+		slog.Bool("discord_unregister_commands", c.Discord.UnregisterCommands),
+	)
+}
+
+// LoadDatabaseConfig reads only the database settings, for commands such as
+// `migrate` that don't connect to Discord
+func LoadDatabaseConfig() (*DatabaseConfig, error) {
+	loadEnvFile()
+
+	config := databaseConfigFromEnv()
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// LoadLogConfig reads the logging settings. Call it after LoadConfig or
+// LoadDatabaseConfig, which load the .env file.
+func LoadLogConfig() LogConfig {
+	return logConfigFromEnv()
+}
+
+func loadEnvFile() {
+	if err := godotenv.Load(); err != nil {
+		slog.Warn(".env file not found or could not be loaded", "error", err)
+	} else {
+		slog.Debug("Loaded .env file")
+	}
+}
+
+func logConfigFromEnv() LogConfig {
+	return LogConfig{
+		Level:  getEnvOrDefault("LOG_LEVEL", "info"),
+		Format: getEnvOrDefault("LOG_FORMAT", "text"),
+	}
+}
+
+func databaseConfigFromEnv() DatabaseConfig {
+	return DatabaseConfig{
+		Driver:   getEnvOrDefault("DB_DRIVER", "postgres"),
+		Path:     getEnvOrDefault("DB_PATH", "memo.db"),
+		Host:     getEnvOrDefault("DB_HOST", "localhost"),
+		Port:     getEnvAsIntOrDefault("DB_PORT", 5432),
+		User:     getEnvOrDefault("DB_USER", "postgres"),
+		Password: os.Getenv("DB_PASSWORD"),
+		DBName:   getEnvOrDefault("DB_NAME", "memodb"),
+		SSLMode:  getEnvOrDefault("DB_SSLMODE", "disable"),
+	}
+}
+
+func (c *DatabaseConfig) validate() error {
+	switch c.Driver {
+	case "postgres":
+		if c.Password == "" {
+			return fmt.Errorf("DB_PASSWORD is required")
+		}
+	case "sqlite":
+	default:
+		return fmt.Errorf("invalid DB_DRIVER %q, use postgres or sqlite", c.Driver)
+	}
+	return nil
+}
+
+func (c *LimitsConfig) validate() error {
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"MAX_PENDING_PER_USER", c.MaxPendingPerUser},
+		{"MAX_PENDING_PER_CHANNEL", c.MaxPendingPerChannel},
+		{"MEMO_RATE_PER_MINUTE", c.MemoRatePerMinute},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			return fmt.Errorf("invalid %s %d, use 0 for no limit or a positive number", limit.name, limit.value)
+		}
+	}
+	if c.MemoRatePerMinute > 0 && c.MemoRateBurst < 1 {
+		return fmt.Errorf("invalid MEMO_RATE_BURST %d, use a positive number", c.MemoRateBurst)
+	}
+	return nil
+}
+
 func (c *DatabaseConfig) ConnectionString() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
 }
 
+// defaultWorkerID derives a per-process ID from the hostname and PID
+func defaultWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "memo-bot"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -105,6 +253,26 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvAsBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a comma-separated variable, skipping empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvAsIntOrDefault(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -24,6 +24,18 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.claimPendingRemindersStmt, err = db.PrepareContext(ctx, claimPendingReminders); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimPendingReminders: %w", err)
+	}
+	if q.countPendingMemosInChannelStmt, err = db.PrepareContext(ctx, countPendingMemosInChannel); err != nil {
+		return nil, fmt.Errorf("error preparing query CountPendingMemosInChannel: %w", err)
+	}
+	if q.countUserPendingMemosStmt, err = db.PrepareContext(ctx, countUserPendingMemos); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUserPendingMemos: %w", err)
+	}
+	if q.countUserPendingMemosInGuildStmt, err = db.PrepareContext(ctx, countUserPendingMemosInGuild); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUserPendingMemosInGuild: %w", err)
+	}
 	if q.createMemoStmt, err = db.PrepareContext(ctx, createMemo); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateMemo: %w", err)
 	}
@@ -33,6 +45,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteMemoStmt, err = db.PrepareContext(ctx, deleteMemo); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMemo: %w", err)
 	}
+	if q.deletePendingLegacyMemosByUserInChannelStmt, err = db.PrepareContext(ctx, deletePendingLegacyMemosByUserInChannel); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePendingLegacyMemosByUserInChannel: %w", err)
+	}
+	if q.deletePendingMemosByUserInGuildStmt, err = db.PrepareContext(ctx, deletePendingMemosByUserInGuild); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePendingMemosByUserInGuild: %w", err)
+	}
+	if q.getGuildSettingsStmt, err = db.PrepareContext(ctx, getGuildSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query GetGuildSettings: %w", err)
+	}
 	if q.getMemoStmt, err = db.PrepareContext(ctx, getMemo); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMemo: %w", err)
 	}
@@ -51,17 +72,76 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listPendingMemosStmt, err = db.PrepareContext(ctx, listPendingMemos); err != nil {
 		return nil, fmt.Errorf("error preparing query ListPendingMemos: %w", err)
 	}
+	if q.listUpcomingRemindersStmt, err = db.PrepareContext(ctx, listUpcomingReminders); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUpcomingReminders: %w", err)
+	}
+	if q.listUserPendingMemosStmt, err = db.PrepareContext(ctx, listUserPendingMemos); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUserPendingMemos: %w", err)
+	}
+	if q.markClaimedMemoAsSentStmt, err = db.PrepareContext(ctx, markClaimedMemoAsSent); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkClaimedMemoAsSent: %w", err)
+	}
 	if q.markMemoAsSentStmt, err = db.PrepareContext(ctx, markMemoAsSent); err != nil {
 		return nil, fmt.Errorf("error preparing query MarkMemoAsSent: %w", err)
 	}
+	if q.reassignMemoStmt, err = db.PrepareContext(ctx, reassignMemo); err != nil {
+		return nil, fmt.Errorf("error preparing query ReassignMemo: %w", err)
+	}
+	if q.recordDeliveryFailureStmt, err = db.PrepareContext(ctx, recordDeliveryFailure); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordDeliveryFailure: %w", err)
+	}
+	if q.recordModerationStmt, err = db.PrepareContext(ctx, recordModeration); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordModeration: %w", err)
+	}
+	if q.renewClaimStmt, err = db.PrepareContext(ctx, renewClaim); err != nil {
+		return nil, fmt.Errorf("error preparing query RenewClaim: %w", err)
+	}
+	if q.rescheduleMemoStmt, err = db.PrepareContext(ctx, rescheduleMemo); err != nil {
+		return nil, fmt.Errorf("error preparing query RescheduleMemo: %w", err)
+	}
+	if q.searchPendingMemosStmt, err = db.PrepareContext(ctx, searchPendingMemos); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchPendingMemos: %w", err)
+	}
+	if q.setUserTimezoneStmt, err = db.PrepareContext(ctx, setUserTimezone); err != nil {
+		return nil, fmt.Errorf("error preparing query SetUserTimezone: %w", err)
+	}
+	if q.snoozeMemoStmt, err = db.PrepareContext(ctx, snoozeMemo); err != nil {
+		return nil, fmt.Errorf("error preparing query SnoozeMemo: %w", err)
+	}
+	if q.updateMemoStmt, err = db.PrepareContext(ctx, updateMemo); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateMemo: %w", err)
+	}
 	if q.updateUserDiscordChannelStmt, err = db.PrepareContext(ctx, updateUserDiscordChannel); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateUserDiscordChannel: %w", err)
 	}
+	if q.upsertGuildSettingsStmt, err = db.PrepareContext(ctx, upsertGuildSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertGuildSettings: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.claimPendingRemindersStmt != nil {
+		if cerr := q.claimPendingRemindersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimPendingRemindersStmt: %w", cerr)
+		}
+	}
+	if q.countPendingMemosInChannelStmt != nil {
+		if cerr := q.countPendingMemosInChannelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countPendingMemosInChannelStmt: %w", cerr)
+		}
+	}
+	if q.countUserPendingMemosStmt != nil {
+		if cerr := q.countUserPendingMemosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUserPendingMemosStmt: %w", cerr)
+		}
+	}
+	if q.countUserPendingMemosInGuildStmt != nil {
+		if cerr := q.countUserPendingMemosInGuildStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUserPendingMemosInGuildStmt: %w", cerr)
+		}
+	}
 	if q.createMemoStmt != nil {
 		if cerr := q.createMemoStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createMemoStmt: %w", cerr)
@@ -77,6 +157,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteMemoStmt: %w", cerr)
 		}
 	}
+	if q.deletePendingLegacyMemosByUserInChannelStmt != nil {
+		if cerr := q.deletePendingLegacyMemosByUserInChannelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePendingLegacyMemosByUserInChannelStmt: %w", cerr)
+		}
+	}
+	if q.deletePendingMemosByUserInGuildStmt != nil {
+		if cerr := q.deletePendingMemosByUserInGuildStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePendingMemosByUserInGuildStmt: %w", cerr)
+		}
+	}
+	if q.getGuildSettingsStmt != nil {
+		if cerr := q.getGuildSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getGuildSettingsStmt: %w", cerr)
+		}
+	}
 	if q.getMemoStmt != nil {
 		if cerr := q.getMemoStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMemoStmt: %w", cerr)
@@ -107,16 +202,81 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listPendingMemosStmt: %w", cerr)
 		}
 	}
+	if q.listUpcomingRemindersStmt != nil {
+		if cerr := q.listUpcomingRemindersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUpcomingRemindersStmt: %w", cerr)
+		}
+	}
+	if q.listUserPendingMemosStmt != nil {
+		if cerr := q.listUserPendingMemosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUserPendingMemosStmt: %w", cerr)
+		}
+	}
+	if q.markClaimedMemoAsSentStmt != nil {
+		if cerr := q.markClaimedMemoAsSentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markClaimedMemoAsSentStmt: %w", cerr)
+		}
+	}
 	if q.markMemoAsSentStmt != nil {
 		if cerr := q.markMemoAsSentStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing markMemoAsSentStmt: %w", cerr)
 		}
 	}
+	if q.reassignMemoStmt != nil {
+		if cerr := q.reassignMemoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing reassignMemoStmt: %w", cerr)
+		}
+	}
+	if q.recordDeliveryFailureStmt != nil {
+		if cerr := q.recordDeliveryFailureStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordDeliveryFailureStmt: %w", cerr)
+		}
+	}
+	if q.recordModerationStmt != nil {
+		if cerr := q.recordModerationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordModerationStmt: %w", cerr)
+		}
+	}
+	if q.renewClaimStmt != nil {
+		if cerr := q.renewClaimStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing renewClaimStmt: %w", cerr)
+		}
+	}
+	if q.rescheduleMemoStmt != nil {
+		if cerr := q.rescheduleMemoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rescheduleMemoStmt: %w", cerr)
+		}
+	}
+	if q.searchPendingMemosStmt != nil {
+		if cerr := q.searchPendingMemosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchPendingMemosStmt: %w", cerr)
+		}
+	}
+	if q.setUserTimezoneStmt != nil {
+		if cerr := q.setUserTimezoneStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setUserTimezoneStmt: %w", cerr)
+		}
+	}
+	if q.snoozeMemoStmt != nil {
+		if cerr := q.snoozeMemoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing snoozeMemoStmt: %w", cerr)
+		}
+	}
+	if q.updateMemoStmt != nil {
+		if cerr := q.updateMemoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateMemoStmt: %w", cerr)
+		}
+	}
 	if q.updateUserDiscordChannelStmt != nil {
 		if cerr := q.updateUserDiscordChannelStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateUserDiscordChannelStmt: %w", cerr)
 		}
 	}
+	if q.upsertGuildSettingsStmt != nil {
+		if cerr := q.upsertGuildSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertGuildSettingsStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -154,35 +314,75 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                               DBTX
-	tx                               *sql.Tx
-	createMemoStmt                   *sql.Stmt
-	createUserStmt                   *sql.Stmt
-	deleteMemoStmt                   *sql.Stmt
-	getMemoStmt                      *sql.Stmt
-	getPendingRemindersStmt          *sql.Stmt
-	getReminderCountsStmt            *sql.Stmt
-	getUserStmt                      *sql.Stmt
-	listAllPendingMemosInChannelStmt *sql.Stmt
-	listPendingMemosStmt             *sql.Stmt
-	markMemoAsSentStmt               *sql.Stmt
-	updateUserDiscordChannelStmt     *sql.Stmt
+	db                                          DBTX
+	tx                                          *sql.Tx
+	claimPendingRemindersStmt                   *sql.Stmt
+	countPendingMemosInChannelStmt              *sql.Stmt
+	countUserPendingMemosStmt                   *sql.Stmt
+	countUserPendingMemosInGuildStmt            *sql.Stmt
+	createMemoStmt                              *sql.Stmt
+	createUserStmt                              *sql.Stmt
+	deleteMemoStmt                              *sql.Stmt
+	deletePendingLegacyMemosByUserInChannelStmt *sql.Stmt
+	deletePendingMemosByUserInGuildStmt         *sql.Stmt
+	getGuildSettingsStmt                        *sql.Stmt
+	getMemoStmt                                 *sql.Stmt
+	getPendingRemindersStmt                     *sql.Stmt
+	getReminderCountsStmt                       *sql.Stmt
+	getUserStmt                                 *sql.Stmt
+	listAllPendingMemosInChannelStmt            *sql.Stmt
+	listPendingMemosStmt                        *sql.Stmt
+	listUpcomingRemindersStmt                   *sql.Stmt
+	listUserPendingMemosStmt                    *sql.Stmt
+	markClaimedMemoAsSentStmt                   *sql.Stmt
+	markMemoAsSentStmt                          *sql.Stmt
+	reassignMemoStmt                            *sql.Stmt
+	recordDeliveryFailureStmt                   *sql.Stmt
+	recordModerationStmt                        *sql.Stmt
+	renewClaimStmt                              *sql.Stmt
+	rescheduleMemoStmt                          *sql.Stmt
+	searchPendingMemosStmt                      *sql.Stmt
+	setUserTimezoneStmt                         *sql.Stmt
+	snoozeMemoStmt                              *sql.Stmt
+	updateMemoStmt                              *sql.Stmt
+	updateUserDiscordChannelStmt                *sql.Stmt
+	upsertGuildSettingsStmt                     *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
 		db:                               tx,
 		tx:                               tx,
+		claimPendingRemindersStmt:        q.claimPendingRemindersStmt,
+		countPendingMemosInChannelStmt:   q.countPendingMemosInChannelStmt,
+		countUserPendingMemosStmt:        q.countUserPendingMemosStmt,
+		countUserPendingMemosInGuildStmt: q.countUserPendingMemosInGuildStmt,
 		createMemoStmt:                   q.createMemoStmt,
 		createUserStmt:                   q.createUserStmt,
 		deleteMemoStmt:                   q.deleteMemoStmt,
-		getMemoStmt:                      q.getMemoStmt,
-		getPendingRemindersStmt:          q.getPendingRemindersStmt,
-		getReminderCountsStmt:            q.getReminderCountsStmt,
-		getUserStmt:                      q.getUserStmt,
-		listAllPendingMemosInChannelStmt: q.listAllPendingMemosInChannelStmt,
-		listPendingMemosStmt:             q.listPendingMemosStmt,
-		markMemoAsSentStmt:               q.markMemoAsSentStmt,
-		updateUserDiscordChannelStmt:     q.updateUserDiscordChannelStmt,
+		deletePendingLegacyMemosByUserInChannelStmt: q.deletePendingLegacyMemosByUserInChannelStmt,
+		deletePendingMemosByUserInGuildStmt:         q.deletePendingMemosByUserInGuildStmt,
+		getGuildSettingsStmt:                        q.getGuildSettingsStmt,
+		getMemoStmt:                                 q.getMemoStmt,
+		getPendingRemindersStmt:                     q.getPendingRemindersStmt,
+		getReminderCountsStmt:                       q.getReminderCountsStmt,
+		getUserStmt:                                 q.getUserStmt,
+		listAllPendingMemosInChannelStmt:            q.listAllPendingMemosInChannelStmt,
+		listPendingMemosStmt:                        q.listPendingMemosStmt,
+		listUpcomingRemindersStmt:                   q.listUpcomingRemindersStmt,
+		listUserPendingMemosStmt:                    q.listUserPendingMemosStmt,
+		markClaimedMemoAsSentStmt:                   q.markClaimedMemoAsSentStmt,
+		markMemoAsSentStmt:                          q.markMemoAsSentStmt,
+		reassignMemoStmt:                            q.reassignMemoStmt,
+		recordDeliveryFailureStmt:                   q.recordDeliveryFailureStmt,
+		recordModerationStmt:                        q.recordModerationStmt,
+		renewClaimStmt:                              q.renewClaimStmt,
+		rescheduleMemoStmt:                          q.rescheduleMemoStmt,
+		searchPendingMemosStmt:                      q.searchPendingMemosStmt,
+		setUserTimezoneStmt:                         q.setUserTimezoneStmt,
+		snoozeMemoStmt:                              q.snoozeMemoStmt,
+		updateMemoStmt:                              q.updateMemoStmt,
+		updateUserDiscordChannelStmt:                q.updateUserDiscordChannelStmt,
+		upsertGuildSettingsStmt:                     q.upsertGuildSettingsStmt,
 	}
 }
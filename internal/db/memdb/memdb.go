@@ -0,0 +1,532 @@
+// Package memdb is an in-memory db.Querier for tests. It mirrors the semantics
+// of the SQL in internal/db/queries.sql: pending means sent = false, lists are
+// ordered by remind_at, and writes filtered by owner silently match nothing.
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"memo-bot/internal/clock"
+	"memo-bot/internal/db"
+)
+
+// Querier stores users, memos, guild settings and the moderation log in
+// memory guarded by a mutex
+type Querier struct {
+	mu         sync.Mutex
+	clock      clock.Clock
+	nextID     int32
+	users      map[string]db.User
+	memos      map[int32]db.Memo
+	guilds     map[string]db.GuildSetting
+	moderation []db.MemoModerationLog
+
+	// moderationErr is returned by RecordModeration, see FailModeration
+	moderationErr error
+}
+
+var _ db.Querier = (*Querier)(nil)
+
+// New returns an empty store. clk plays the role of the database's NOW().
+func New(clk clock.Clock) *Querier {
+	return &Querier{
+		clock:  clk,
+		nextID: 1,
+		users:  make(map[string]db.User),
+		memos:  make(map[int32]db.Memo),
+		guilds: make(map[string]db.GuildSetting),
+	}
+}
+
+// Memos returns a snapshot of every stored memo ordered by ID, including sent ones
+func (q *Querier) Memos() []db.Memo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	memos := make([]db.Memo, 0, len(q.memos))
+	for _, m := range q.memos {
+		memos = append(memos, m)
+	}
+	sort.Slice(memos, func(i, j int) bool { return memos[i].ID < memos[j].ID })
+	return memos
+}
+
+// ModerationLog returns a snapshot of the moderation log in insertion order
+func (q *Querier) ModerationLog() []db.MemoModerationLog {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]db.MemoModerationLog(nil), q.moderation...)
+}
+
+// FailModeration makes RecordModeration return err, or succeed again if err is nil
+func (q *Querier) FailModeration(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.moderationErr = err
+}
+
+// InTx runs fn on the store and restores its previous contents if fn fails.
+// Unlike a database transaction it doesn't isolate fn from concurrent writes.
+func (q *Querier) InTx(ctx context.Context, fn func(q db.Querier) error) error {
+	q.mu.Lock()
+	saved := Querier{
+		nextID:     q.nextID,
+		users:      make(map[string]db.User, len(q.users)),
+		memos:      make(map[int32]db.Memo, len(q.memos)),
+		guilds:     make(map[string]db.GuildSetting, len(q.guilds)),
+		moderation: append([]db.MemoModerationLog(nil), q.moderation...),
+	}
+	for k, v := range q.users {
+		saved.users[k] = v
+	}
+	for k, v := range q.memos {
+		saved.memos[k] = v
+	}
+	for k, v := range q.guilds {
+		saved.guilds[k] = v
+	}
+	q.mu.Unlock()
+
+	if err := fn(q); err != nil {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.nextID, q.users, q.memos, q.guilds, q.moderation = saved.nextID, saved.users, saved.memos, saved.guilds, saved.moderation
+		return err
+	}
+	return nil
+}
+
+func (q *Querier) ClaimPendingReminders(ctx context.Context, arg db.ClaimPendingRemindersParams) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	due := q.filter(func(m db.Memo) bool {
+		return pending(m) && !m.Failed &&
+			!m.RemindAt.After(arg.Now) &&
+			(!m.ClaimedUntil.Valid || m.ClaimedUntil.Time.Before(arg.Now)) &&
+			(!m.NextAttemptAt.Valid || !m.NextAttemptAt.Time.After(arg.Now))
+	}, int(arg.BatchSize))
+
+	for i := range due {
+		due[i].ClaimedBy = arg.WorkerID
+		due[i].ClaimedUntil = arg.LeaseUntil
+		q.memos[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (q *Querier) CountPendingMemosInChannel(ctx context.Context, discordChannelID string) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var count int64
+	for _, m := range q.memos {
+		if m.DiscordChannelID == discordChannelID && pending(m) && !m.Failed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *Querier) CountUserPendingMemos(ctx context.Context, discordUserID string) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var count int64
+	for _, m := range q.memos {
+		if m.DiscordUserID == discordUserID && pending(m) && !m.Failed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *Querier) CountUserPendingMemosInGuild(ctx context.Context, arg db.CountUserPendingMemosInGuildParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var count int64
+	for _, m := range q.memos {
+		if m.DiscordUserID == arg.DiscordUserID && m.GuildID.Valid && arg.GuildID.Valid &&
+			m.GuildID.String == arg.GuildID.String && pending(m) && !m.Failed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *Querier) CreateMemo(ctx context.Context, arg db.CreateMemoParams) (db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.clock.Now()
+	if !arg.RemindAt.After(now) {
+		return db.Memo{}, fmt.Errorf(`new row for relation "memos" violates check constraint "remind_at_check"`)
+	}
+
+	m := db.Memo{
+		ID:               q.nextID,
+		DiscordUserID:    arg.DiscordUserID,
+		DiscordChannelID: arg.DiscordChannelID,
+		Content:          arg.Content,
+		CreatedAt:        sql.NullTime{Time: now, Valid: true},
+		RemindAt:         arg.RemindAt,
+		Sent:             sql.NullBool{Bool: false, Valid: true},
+		Recurrence:       arg.Recurrence,
+		Delivery:         arg.Delivery,
+		GuildID:          arg.GuildID,
+		SourceMessageID:  arg.SourceMessageID,
+		Mentions:         arg.Mentions,
+	}
+	q.nextID++
+	q.memos[m.ID] = m
+	return m, nil
+}
+
+func (q *Querier) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.users[arg.UserID]; ok {
+		return db.User{}, fmt.Errorf(`duplicate key value violates unique constraint "users_pkey"`)
+	}
+	u := db.User{UserID: arg.UserID, Username: arg.Username, DiscordChannelID: arg.DiscordChannelID}
+	q.users[u.UserID] = u
+	return u, nil
+}
+
+func (q *Querier) DeleteMemo(ctx context.Context, arg db.DeleteMemoParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if m, ok := q.memos[arg.ID]; ok && m.DiscordUserID == arg.DiscordUserID {
+		delete(q.memos, arg.ID)
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (q *Querier) DeletePendingLegacyMemosByUserInChannel(ctx context.Context, arg db.DeletePendingLegacyMemosByUserInChannelParams) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	deleted := q.filter(func(m db.Memo) bool {
+		return m.DiscordUserID == arg.DiscordUserID && m.DiscordChannelID == arg.DiscordChannelID &&
+			!m.GuildID.Valid && pending(m)
+	}, 0)
+	for _, m := range deleted {
+		delete(q.memos, m.ID)
+	}
+	return deleted, nil
+}
+
+func (q *Querier) DeletePendingMemosByUserInGuild(ctx context.Context, arg db.DeletePendingMemosByUserInGuildParams) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	deleted := q.filter(func(m db.Memo) bool {
+		return m.DiscordUserID == arg.DiscordUserID && m.GuildID.Valid && arg.GuildID.Valid &&
+			m.GuildID.String == arg.GuildID.String && pending(m)
+	}, 0)
+	for _, m := range deleted {
+		delete(q.memos, m.ID)
+	}
+	return deleted, nil
+}
+
+func (q *Querier) GetGuildSettings(ctx context.Context, guildID string) (db.GuildSetting, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	g, ok := q.guilds[guildID]
+	if !ok {
+		return db.GuildSetting{}, sql.ErrNoRows
+	}
+	return g, nil
+}
+
+func (q *Querier) GetMemo(ctx context.Context, id int32) (db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m, ok := q.memos[id]
+	if !ok {
+		return db.Memo{}, sql.ErrNoRows
+	}
+	return m, nil
+}
+
+func (q *Querier) GetPendingReminders(ctx context.Context, remindAt time.Time) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.filter(func(m db.Memo) bool {
+		return pending(m) && !m.RemindAt.After(remindAt)
+	}, 0), nil
+}
+
+func (q *Querier) GetReminderCounts(ctx context.Context, discordUserID string) ([]db.GetReminderCountsRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	counts := make(map[string]int64)
+	for _, m := range q.memos {
+		if m.DiscordUserID == discordUserID && pending(m) {
+			counts[m.DiscordChannelID]++
+		}
+	}
+
+	rows := make([]db.GetReminderCountsRow, 0, len(counts))
+	for channelID, count := range counts {
+		rows = append(rows, db.GetReminderCountsRow{DiscordChannelID: channelID, Count: count})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].DiscordChannelID < rows[j].DiscordChannelID })
+	return rows, nil
+}
+
+func (q *Querier) GetUser(ctx context.Context, userID string) (db.User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	u, ok := q.users[userID]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func (q *Querier) ListAllPendingMemosInChannel(ctx context.Context, discordChannelID string) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.clock.Now()
+	return q.filter(func(m db.Memo) bool {
+		return m.DiscordChannelID == discordChannelID && m.RemindAt.After(now) && pending(m)
+	}, 0), nil
+}
+
+func (q *Querier) ListPendingMemos(ctx context.Context, arg db.ListPendingMemosParams) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.filter(func(m db.Memo) bool {
+		return m.DiscordUserID == arg.DiscordUserID && m.DiscordChannelID == arg.DiscordChannelID && pending(m)
+	}, 0), nil
+}
+
+func (q *Querier) ListUpcomingReminders(ctx context.Context, limit int32) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.filter(func(m db.Memo) bool {
+		return pending(m) && !m.Failed
+	}, int(limit)), nil
+}
+
+func (q *Querier) ListUserPendingMemos(ctx context.Context, discordUserID string) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.filter(func(m db.Memo) bool {
+		return m.DiscordUserID == discordUserID && pending(m)
+	}, 0), nil
+}
+
+func (q *Querier) MarkClaimedMemoAsSent(ctx context.Context, arg db.MarkClaimedMemoAsSentParams) (int64, error) {
+	return q.updateClaimed(arg.ID, arg.ClaimedBy, func(m *db.Memo) {
+		m.Sent = sql.NullBool{Bool: true, Valid: true}
+		clearClaim(m)
+		clearRetries(m)
+	}), nil
+}
+
+func (q *Querier) MarkMemoAsSent(ctx context.Context, id int32) error {
+	q.update(id, func(m *db.Memo) {
+		m.Sent = sql.NullBool{Bool: true, Valid: true}
+		clearClaim(m)
+		clearRetries(m)
+	})
+	return nil
+}
+
+func (q *Querier) ReassignMemo(ctx context.Context, arg db.ReassignMemoParams) (db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m, ok := q.memos[arg.ID]
+	if !ok || !pending(m) {
+		return db.Memo{}, sql.ErrNoRows
+	}
+	m.DiscordUserID = arg.DiscordUserID
+	q.memos[m.ID] = m
+	return m, nil
+}
+
+func (q *Querier) RecordDeliveryFailure(ctx context.Context, arg db.RecordDeliveryFailureParams) (int64, error) {
+	return q.updateClaimed(arg.ID, arg.ClaimedBy, func(m *db.Memo) {
+		m.Attempts++
+		m.LastError = arg.LastError
+		m.NextAttemptAt = arg.NextAttemptAt
+		m.Failed = arg.Failed
+		clearClaim(m)
+	}), nil
+}
+
+func (q *Querier) RecordModeration(ctx context.Context, arg db.RecordModerationParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.moderationErr != nil {
+		return q.moderationErr
+	}
+	q.moderation = append(q.moderation, db.MemoModerationLog{
+		ID:          int32(len(q.moderation) + 1),
+		MemoID:      arg.MemoID,
+		GuildID:     arg.GuildID,
+		Action:      arg.Action,
+		ModeratorID: arg.ModeratorID,
+		OwnerID:     arg.OwnerID,
+		NewOwnerID:  arg.NewOwnerID,
+		CreatedAt:   sql.NullTime{Time: q.clock.Now(), Valid: true},
+	})
+	return nil
+}
+
+func (q *Querier) RenewClaim(ctx context.Context, arg db.RenewClaimParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m, ok := q.memos[arg.ID]
+	if !ok || !pending(m) || !claimedBy(m, arg.ClaimedBy) {
+		return 0, nil
+	}
+	m.ClaimedUntil = arg.ClaimedUntil
+	q.memos[arg.ID] = m
+	return 1, nil
+}
+
+func (q *Querier) RescheduleMemo(ctx context.Context, arg db.RescheduleMemoParams) (int64, error) {
+	return q.updateClaimed(arg.ID, arg.ClaimedBy, func(m *db.Memo) {
+		m.RemindAt = arg.RemindAt
+		clearClaim(m)
+		clearRetries(m)
+	}), nil
+}
+
+var likeUnescaper = strings.NewReplacer(`\\`, `\`, `\%`, `%`, `\_`, `_`)
+
+func (q *Querier) SearchPendingMemos(ctx context.Context, arg db.SearchPendingMemosParams) ([]db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// The prefix arrives escaped for LIKE ... ESCAPE '\'
+	prefix := strings.ToLower(likeUnescaper.Replace(arg.Prefix.String))
+	return q.filter(func(m db.Memo) bool {
+		return m.DiscordUserID == arg.DiscordUserID && pending(m) &&
+			(strings.HasPrefix(strings.ToLower(m.Content), prefix) ||
+				strings.HasPrefix(strconv.Itoa(int(m.ID)), prefix))
+	}, int(arg.MaxResults)), nil
+}
+
+func (q *Querier) SetUserTimezone(ctx context.Context, arg db.SetUserTimezoneParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	u := q.users[arg.UserID]
+	u.UserID = arg.UserID
+	u.Username = arg.Username
+	u.Timezone = arg.Timezone
+	q.users[arg.UserID] = u
+	return nil
+}
+
+func (q *Querier) SnoozeMemo(ctx context.Context, arg db.SnoozeMemoParams) error {
+	q.update(arg.ID, func(m *db.Memo) {
+		m.RemindAt = arg.RemindAt
+		m.Sent = sql.NullBool{Bool: false, Valid: true}
+		clearClaim(m)
+	})
+	return nil
+}
+
+func (q *Querier) UpdateMemo(ctx context.Context, arg db.UpdateMemoParams) (db.Memo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m, ok := q.memos[arg.ID]
+	if !ok || m.DiscordUserID != arg.DiscordUserID || !pending(m) {
+		return db.Memo{}, sql.ErrNoRows
+	}
+	m.Content = arg.Content
+	m.RemindAt = arg.RemindAt
+	m.Failed = false
+	clearRetries(&m)
+	clearClaim(&m)
+	q.memos[m.ID] = m
+	return m, nil
+}
+
+func (q *Querier) UpdateUserDiscordChannel(ctx context.Context, arg db.UpdateUserDiscordChannelParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if u, ok := q.users[arg.UserID]; ok {
+		u.DiscordChannelID = arg.DiscordChannelID
+		q.users[arg.UserID] = u
+	}
+	return nil
+}
+
+func (q *Querier) UpsertGuildSettings(ctx context.Context, arg db.UpsertGuildSettingsParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.guilds[arg.GuildID] = db.GuildSetting(arg)
+	return nil
+}
+
+// filter returns the memos matching keep ordered by remind_at, then ID. A
+// positive limit caps the result like SQL's LIMIT. The caller holds q.mu.
+func (q *Querier) filter(keep func(db.Memo) bool, limit int) []db.Memo {
+	var memos []db.Memo
+	for _, m := range q.memos {
+		if keep(m) {
+			memos = append(memos, m)
+		}
+	}
+	sort.Slice(memos, func(i, j int) bool {
+		if !memos[i].RemindAt.Equal(memos[j].RemindAt) {
+			return memos[i].RemindAt.Before(memos[j].RemindAt)
+		}
+		return memos[i].ID < memos[j].ID
+	})
+	if limit > 0 && len(memos) > limit {
+		memos = memos[:limit]
+	}
+	return memos
+}
+
+// update applies fn to the memo with the given ID, if it exists
+func (q *Querier) update(id int32, fn func(m *db.Memo)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if m, ok := q.memos[id]; ok {
+		fn(&m)
+		q.memos[id] = m
+	}
+}
+
+// updateClaimed applies fn to a memo claimed by workerID and returns the
+// number of rows an UPDATE ... AND claimed_by = workerID would affect
+func (q *Querier) updateClaimed(id int32, workerID sql.NullString, fn func(m *db.Memo)) int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m, ok := q.memos[id]
+	if !ok || !claimedBy(m, workerID) {
+		return 0
+	}
+	fn(&m)
+	q.memos[id] = m
+	return 1
+}
+
+// claimedBy mirrors "claimed_by = workerID", which is not true for NULLs
+func claimedBy(m db.Memo, workerID sql.NullString) bool {
+	return m.ClaimedBy.Valid && workerID.Valid && m.ClaimedBy.String == workerID.String
+}
+
+// pending mirrors "sent = false", which is not true for a NULL sent
+func pending(m db.Memo) bool {
+	return m.Sent.Valid && !m.Sent.Bool
+}
+
+func clearClaim(m *db.Memo) {
+	m.ClaimedBy = sql.NullString{}
+	m.ClaimedUntil = sql.NullTime{}
+}
+
+func clearRetries(m *db.Memo) {
+	m.Attempts = 0
+	m.LastError = sql.NullString{}
+	m.NextAttemptAt = sql.NullTime{}
+}
@@ -9,18 +9,49 @@ import (
 	"time"
 )
 
+type GuildSetting struct {
+	GuildID           string         `json:"guild_id"`
+	Timezone          sql.NullString `json:"timezone"`
+	DeliveryChannelID sql.NullString `json:"delivery_channel_id"`
+	ListShowOthers    bool           `json:"list_show_others"`
+	MaxPendingPerUser int32          `json:"max_pending_per_user"`
+}
+
 type Memo struct {
-	ID               int32        `json:"id"`
-	DiscordUserID    string       `json:"discord_user_id"`
-	DiscordChannelID string       `json:"discord_channel_id"`
-	Content          string       `json:"content"`
-	CreatedAt        sql.NullTime `json:"created_at"`
-	RemindAt         time.Time    `json:"remind_at"`
-	Sent             sql.NullBool `json:"sent"`
+	ID               int32          `json:"id"`
+	DiscordUserID    string         `json:"discord_user_id"`
+	DiscordChannelID string         `json:"discord_channel_id"`
+	Content          string         `json:"content"`
+	CreatedAt        sql.NullTime   `json:"created_at"`
+	RemindAt         time.Time      `json:"remind_at"`
+	Sent             sql.NullBool   `json:"sent"`
+	Recurrence       sql.NullString `json:"recurrence"`
+	Delivery         string         `json:"delivery"`
+	ClaimedBy        sql.NullString `json:"claimed_by"`
+	ClaimedUntil     sql.NullTime   `json:"claimed_until"`
+	Attempts         int32          `json:"attempts"`
+	LastError        sql.NullString `json:"last_error"`
+	NextAttemptAt    sql.NullTime   `json:"next_attempt_at"`
+	Failed           bool           `json:"failed"`
+	GuildID          sql.NullString `json:"guild_id"`
+	SourceMessageID  sql.NullString `json:"source_message_id"`
+	Mentions         sql.NullString `json:"mentions"`
+}
+
+type MemoModerationLog struct {
+	ID          int32          `json:"id"`
+	MemoID      int32          `json:"memo_id"`
+	GuildID     sql.NullString `json:"guild_id"`
+	Action      string         `json:"action"`
+	ModeratorID string         `json:"moderator_id"`
+	OwnerID     string         `json:"owner_id"`
+	NewOwnerID  sql.NullString `json:"new_owner_id"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
 }
 
 type User struct {
 	UserID           string         `json:"user_id"`
 	Username         string         `json:"username"`
 	DiscordChannelID sql.NullString `json:"discord_channel_id"`
+	Timezone         sql.NullString `json:"timezone"`
 }
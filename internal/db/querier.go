@@ -10,17 +10,42 @@ import (
 )
 
 type Querier interface {
+	// Leases due memos to one worker. SKIP LOCKED lets several bot instances claim
+	// concurrently without blocking, and expired leases of crashed workers are
+	// claimable again.
+	ClaimPendingReminders(ctx context.Context, arg ClaimPendingRemindersParams) ([]Memo, error)
+	CountPendingMemosInChannel(ctx context.Context, discordChannelID string) (int64, error)
+	CountUserPendingMemos(ctx context.Context, discordUserID string) (int64, error)
+	CountUserPendingMemosInGuild(ctx context.Context, arg CountUserPendingMemosInGuildParams) (int64, error)
 	CreateMemo(ctx context.Context, arg CreateMemoParams) (Memo, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteMemo(ctx context.Context, arg DeleteMemoParams) error
+	DeleteMemo(ctx context.Context, arg DeleteMemoParams) (int64, error)
+	DeletePendingLegacyMemosByUserInChannel(ctx context.Context, arg DeletePendingLegacyMemosByUserInChannelParams) ([]Memo, error)
+	DeletePendingMemosByUserInGuild(ctx context.Context, arg DeletePendingMemosByUserInGuildParams) ([]Memo, error)
+	GetGuildSettings(ctx context.Context, guildID string) (GuildSetting, error)
 	GetMemo(ctx context.Context, id int32) (Memo, error)
 	GetPendingReminders(ctx context.Context, remindAt time.Time) ([]Memo, error)
 	GetReminderCounts(ctx context.Context, discordUserID string) ([]GetReminderCountsRow, error)
 	GetUser(ctx context.Context, userID string) (User, error)
 	ListAllPendingMemosInChannel(ctx context.Context, discordChannelID string) ([]Memo, error)
 	ListPendingMemos(ctx context.Context, arg ListPendingMemosParams) ([]Memo, error)
+	ListUpcomingReminders(ctx context.Context, limit int32) ([]Memo, error)
+	ListUserPendingMemos(ctx context.Context, discordUserID string) ([]Memo, error)
+	MarkClaimedMemoAsSent(ctx context.Context, arg MarkClaimedMemoAsSentParams) (int64, error)
 	MarkMemoAsSent(ctx context.Context, id int32) error
+	ReassignMemo(ctx context.Context, arg ReassignMemoParams) (Memo, error)
+	RecordDeliveryFailure(ctx context.Context, arg RecordDeliveryFailureParams) (int64, error)
+	RecordModeration(ctx context.Context, arg RecordModerationParams) error
+	// Extends a worker's lease right before it sends a memo. No rows means the
+	// lease expired and another worker may have reclaimed the memo.
+	RenewClaim(ctx context.Context, arg RenewClaimParams) (int64, error)
+	RescheduleMemo(ctx context.Context, arg RescheduleMemoParams) (int64, error)
+	SearchPendingMemos(ctx context.Context, arg SearchPendingMemosParams) ([]Memo, error)
+	SetUserTimezone(ctx context.Context, arg SetUserTimezoneParams) error
+	SnoozeMemo(ctx context.Context, arg SnoozeMemoParams) error
+	UpdateMemo(ctx context.Context, arg UpdateMemoParams) (Memo, error)
 	UpdateUserDiscordChannel(ctx context.Context, arg UpdateUserDiscordChannelParams) error
+	UpsertGuildSettings(ctx context.Context, arg UpsertGuildSettingsParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,9 +12,15 @@ UPDATE users
 SET discord_channel_id = $2
 WHERE user_id = $1;
 
+-- name: SetUserTimezone :exec
+INSERT INTO users (user_id, username, timezone)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET username = EXCLUDED.username, timezone = EXCLUDED.timezone;
+
 -- name: CreateMemo :one
-INSERT INTO memos (discord_user_id, discord_channel_id, content, remind_at)
-VALUES ($1, $2, $3, $4)
+INSERT INTO memos (discord_user_id, discord_channel_id, content, remind_at, recurrence, delivery, guild_id, source_message_id, mentions)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 
 -- name: ListPendingMemos :many
@@ -22,6 +28,19 @@ SELECT * FROM memos
 WHERE discord_user_id = $1 AND discord_channel_id = $2 AND sent = false
 ORDER BY remind_at;
 
+-- name: ListUserPendingMemos :many
+SELECT * FROM memos
+WHERE discord_user_id = $1 AND sent = false
+ORDER BY remind_at;
+
+-- name: SearchPendingMemos :many
+SELECT * FROM memos
+WHERE discord_user_id = sqlc.arg(discord_user_id)
+  AND sent = false
+  AND (content ILIKE (sqlc.arg(prefix) || '%') ESCAPE '\' OR CAST(id AS TEXT) LIKE (sqlc.arg(prefix) || '%') ESCAPE '\')
+ORDER BY remind_at
+LIMIT sqlc.arg(max_results);
+
 -- name: GetReminderCounts :many
 SELECT discord_channel_id, COUNT(*) as count
 FROM memos
@@ -34,12 +53,78 @@ FROM memos
 WHERE sent = false AND remind_at <= $1
 ORDER BY remind_at;
 
+-- name: ClaimPendingReminders :many
+-- Leases due memos to one worker. SKIP LOCKED lets several bot instances claim
+-- concurrently without blocking, and expired leases of crashed workers are
+-- claimable again.
+UPDATE memos
+SET claimed_by = sqlc.arg(worker_id), claimed_until = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT id
+    FROM memos
+    WHERE sent = false
+      AND failed = false
+      AND remind_at <= sqlc.arg(now)
+      AND (claimed_until IS NULL OR claimed_until < sqlc.arg(now))
+      AND (next_attempt_at IS NULL OR next_attempt_at <= sqlc.arg(now))
+    ORDER BY remind_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RenewClaim :execrows
+-- Extends a worker's lease right before it sends a memo. No rows means the
+-- lease expired and another worker may have reclaimed the memo.
+UPDATE memos
+SET claimed_until = $3
+WHERE id = $1 AND claimed_by = $2 AND sent = false;
+
+-- name: RecordDeliveryFailure :execrows
+UPDATE memos
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, failed = $4,
+    claimed_by = NULL, claimed_until = NULL
+WHERE id = $1 AND claimed_by = $5;
+
+-- name: ListUpcomingReminders :many
+SELECT *
+FROM memos
+WHERE sent = false AND failed = false
+ORDER BY remind_at
+LIMIT $1;
+
 -- name: MarkMemoAsSent :exec
 UPDATE memos
-SET sent = true
+SET sent = true, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
 WHERE id = $1;
 
--- name: DeleteMemo :exec
+-- name: MarkClaimedMemoAsSent :execrows
+UPDATE memos
+SET sent = true, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
+WHERE id = $1 AND claimed_by = $2;
+
+-- name: RescheduleMemo :execrows
+UPDATE memos
+SET remind_at = $2, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
+WHERE id = $1 AND claimed_by = $3;
+
+-- name: SnoozeMemo :exec
+UPDATE memos
+SET remind_at = $2, sent = false, claimed_by = NULL, claimed_until = NULL
+WHERE id = $1;
+
+-- name: UpdateMemo :one
+UPDATE memos
+SET content = $3, remind_at = $4, failed = false,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL,
+    claimed_by = NULL, claimed_until = NULL
+WHERE id = $1 AND discord_user_id = $2 AND sent = false
+RETURNING *;
+
+-- name: DeleteMemo :execrows
 DELETE FROM memos
 WHERE id = $1 AND discord_user_id = $2;
 
@@ -53,4 +138,47 @@ ORDER BY remind_at ASC;
 
 -- name: GetMemo :one
 SELECT * FROM memos
-WHERE id = $1; 
\ No newline at end of file
+WHERE id = $1;
+
+-- name: CountUserPendingMemos :one
+SELECT COUNT(*) FROM memos
+WHERE discord_user_id = $1 AND sent = false AND failed = false;
+
+-- name: CountPendingMemosInChannel :one
+SELECT COUNT(*) FROM memos
+WHERE discord_channel_id = $1 AND sent = false AND failed = false;
+
+-- name: CountUserPendingMemosInGuild :one
+SELECT COUNT(*) FROM memos
+WHERE discord_user_id = $1 AND guild_id = $2 AND sent = false AND failed = false;
+
+-- name: GetGuildSettings :one
+SELECT * FROM guild_settings
+WHERE guild_id = $1;
+
+-- name: UpsertGuildSettings :exec
+INSERT INTO guild_settings (guild_id, timezone, delivery_channel_id, list_show_others, max_pending_per_user)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (guild_id) DO UPDATE
+SET timezone = EXCLUDED.timezone, delivery_channel_id = EXCLUDED.delivery_channel_id,
+    list_show_others = EXCLUDED.list_show_others, max_pending_per_user = EXCLUDED.max_pending_per_user;
+
+-- name: ReassignMemo :one
+UPDATE memos
+SET discord_user_id = $2
+WHERE id = $1 AND sent = false
+RETURNING *;
+
+-- name: DeletePendingMemosByUserInGuild :many
+DELETE FROM memos
+WHERE discord_user_id = $1 AND guild_id = $2 AND sent = false
+RETURNING *;
+
+-- name: DeletePendingLegacyMemosByUserInChannel :many
+DELETE FROM memos
+WHERE discord_user_id = $1 AND discord_channel_id = $2 AND guild_id IS NULL AND sent = false
+RETURNING *;
+
+-- name: RecordModeration :exec
+INSERT INTO memo_moderation_log (memo_id, guild_id, action, moderator_id, owner_id, new_owner_id)
+VALUES ($1, $2, $3, $4, $5, $6);
@@ -11,17 +11,138 @@ import (
 	"time"
 )
 
+const claimPendingReminders = `-- name: ClaimPendingReminders :many
+UPDATE memos
+SET claimed_by = $1, claimed_until = $2
+WHERE id IN (
+    SELECT id
+    FROM memos
+    WHERE sent = false
+      AND failed = false
+      AND remind_at <= $3
+      AND (claimed_until IS NULL OR claimed_until < $3)
+      AND (next_attempt_at IS NULL OR next_attempt_at <= $3)
+    ORDER BY remind_at
+    LIMIT $4
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
+`
+
+type ClaimPendingRemindersParams struct {
+	WorkerID   sql.NullString `json:"worker_id"`
+	LeaseUntil sql.NullTime   `json:"lease_until"`
+	Now        time.Time      `json:"now"`
+	BatchSize  int32          `json:"batch_size"`
+}
+
+// Leases due memos to one worker. SKIP LOCKED lets several bot instances claim
+// concurrently without blocking, and expired leases of crashed workers are
+// claimable again.
+func (q *Queries) ClaimPendingReminders(ctx context.Context, arg ClaimPendingRemindersParams) ([]Memo, error) {
+	rows, err := q.query(ctx, q.claimPendingRemindersStmt, claimPendingReminders,
+		arg.WorkerID,
+		arg.LeaseUntil,
+		arg.Now,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Memo
+	for rows.Next() {
+		var i Memo
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUserID,
+			&i.DiscordChannelID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.RemindAt,
+			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countPendingMemosInChannel = `-- name: CountPendingMemosInChannel :one
+SELECT COUNT(*) FROM memos
+WHERE discord_channel_id = $1 AND sent = false AND failed = false
+`
+
+func (q *Queries) CountPendingMemosInChannel(ctx context.Context, discordChannelID string) (int64, error) {
+	row := q.queryRow(ctx, q.countPendingMemosInChannelStmt, countPendingMemosInChannel, discordChannelID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserPendingMemos = `-- name: CountUserPendingMemos :one
+SELECT COUNT(*) FROM memos
+WHERE discord_user_id = $1 AND sent = false AND failed = false
+`
+
+func (q *Queries) CountUserPendingMemos(ctx context.Context, discordUserID string) (int64, error) {
+	row := q.queryRow(ctx, q.countUserPendingMemosStmt, countUserPendingMemos, discordUserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserPendingMemosInGuild = `-- name: CountUserPendingMemosInGuild :one
+SELECT COUNT(*) FROM memos
+WHERE discord_user_id = $1 AND guild_id = $2 AND sent = false AND failed = false
+`
+
+type CountUserPendingMemosInGuildParams struct {
+	DiscordUserID string         `json:"discord_user_id"`
+	GuildID       sql.NullString `json:"guild_id"`
+}
+
+func (q *Queries) CountUserPendingMemosInGuild(ctx context.Context, arg CountUserPendingMemosInGuildParams) (int64, error) {
+	row := q.queryRow(ctx, q.countUserPendingMemosInGuildStmt, countUserPendingMemosInGuild, arg.DiscordUserID, arg.GuildID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createMemo = `-- name: CreateMemo :one
-INSERT INTO memos (discord_user_id, discord_channel_id, content, remind_at)
-VALUES ($1, $2, $3, $4)
-RETURNING id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent
+INSERT INTO memos (discord_user_id, discord_channel_id, content, remind_at, recurrence, delivery, guild_id, source_message_id, mentions)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
 `
 
 type CreateMemoParams struct {
-	DiscordUserID    string    `json:"discord_user_id"`
-	DiscordChannelID string    `json:"discord_channel_id"`
-	Content          string    `json:"content"`
-	RemindAt         time.Time `json:"remind_at"`
+	DiscordUserID    string         `json:"discord_user_id"`
+	DiscordChannelID string         `json:"discord_channel_id"`
+	Content          string         `json:"content"`
+	RemindAt         time.Time      `json:"remind_at"`
+	Recurrence       sql.NullString `json:"recurrence"`
+	Delivery         string         `json:"delivery"`
+	GuildID          sql.NullString `json:"guild_id"`
+	SourceMessageID  sql.NullString `json:"source_message_id"`
+	Mentions         sql.NullString `json:"mentions"`
 }
 
 func (q *Queries) CreateMemo(ctx context.Context, arg CreateMemoParams) (Memo, error) {
@@ -30,6 +151,11 @@ func (q *Queries) CreateMemo(ctx context.Context, arg CreateMemoParams) (Memo, e
 		arg.DiscordChannelID,
 		arg.Content,
 		arg.RemindAt,
+		arg.Recurrence,
+		arg.Delivery,
+		arg.GuildID,
+		arg.SourceMessageID,
+		arg.Mentions,
 	)
 	var i Memo
 	err := row.Scan(
@@ -40,6 +166,17 @@ func (q *Queries) CreateMemo(ctx context.Context, arg CreateMemoParams) (Memo, e
 		&i.CreatedAt,
 		&i.RemindAt,
 		&i.Sent,
+		&i.Recurrence,
+		&i.Delivery,
+		&i.ClaimedBy,
+		&i.ClaimedUntil,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.Failed,
+		&i.GuildID,
+		&i.SourceMessageID,
+		&i.Mentions,
 	)
 	return i, err
 }
@@ -47,7 +184,7 @@ func (q *Queries) CreateMemo(ctx context.Context, arg CreateMemoParams) (Memo, e
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (user_id, username, discord_channel_id)
 VALUES ($1, $2, $3)
-RETURNING user_id, username, discord_channel_id
+RETURNING user_id, username, discord_channel_id, timezone
 `
 
 type CreateUserParams struct {
@@ -59,11 +196,16 @@ type CreateUserParams struct {
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
 	row := q.queryRow(ctx, q.createUserStmt, createUser, arg.UserID, arg.Username, arg.DiscordChannelID)
 	var i User
-	err := row.Scan(&i.UserID, &i.Username, &i.DiscordChannelID)
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.DiscordChannelID,
+		&i.Timezone,
+	)
 	return i, err
 }
 
-const deleteMemo = `-- name: DeleteMemo :exec
+const deleteMemo = `-- name: DeleteMemo :execrows
 DELETE FROM memos
 WHERE id = $1 AND discord_user_id = $2
 `
@@ -73,13 +215,140 @@ type DeleteMemoParams struct {
 	DiscordUserID string `json:"discord_user_id"`
 }
 
-func (q *Queries) DeleteMemo(ctx context.Context, arg DeleteMemoParams) error {
-	_, err := q.exec(ctx, q.deleteMemoStmt, deleteMemo, arg.ID, arg.DiscordUserID)
-	return err
+func (q *Queries) DeleteMemo(ctx context.Context, arg DeleteMemoParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteMemoStmt, deleteMemo, arg.ID, arg.DiscordUserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePendingLegacyMemosByUserInChannel = `-- name: DeletePendingLegacyMemosByUserInChannel :many
+DELETE FROM memos
+WHERE discord_user_id = $1 AND discord_channel_id = $2 AND guild_id IS NULL AND sent = false
+RETURNING id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
+`
+
+type DeletePendingLegacyMemosByUserInChannelParams struct {
+	DiscordUserID    string `json:"discord_user_id"`
+	DiscordChannelID string `json:"discord_channel_id"`
+}
+
+func (q *Queries) DeletePendingLegacyMemosByUserInChannel(ctx context.Context, arg DeletePendingLegacyMemosByUserInChannelParams) ([]Memo, error) {
+	rows, err := q.query(ctx, q.deletePendingLegacyMemosByUserInChannelStmt, deletePendingLegacyMemosByUserInChannel, arg.DiscordUserID, arg.DiscordChannelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Memo
+	for rows.Next() {
+		var i Memo
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUserID,
+			&i.DiscordChannelID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.RemindAt,
+			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deletePendingMemosByUserInGuild = `-- name: DeletePendingMemosByUserInGuild :many
+DELETE FROM memos
+WHERE discord_user_id = $1 AND guild_id = $2 AND sent = false
+RETURNING id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
+`
+
+type DeletePendingMemosByUserInGuildParams struct {
+	DiscordUserID string         `json:"discord_user_id"`
+	GuildID       sql.NullString `json:"guild_id"`
+}
+
+func (q *Queries) DeletePendingMemosByUserInGuild(ctx context.Context, arg DeletePendingMemosByUserInGuildParams) ([]Memo, error) {
+	rows, err := q.query(ctx, q.deletePendingMemosByUserInGuildStmt, deletePendingMemosByUserInGuild, arg.DiscordUserID, arg.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Memo
+	for rows.Next() {
+		var i Memo
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUserID,
+			&i.DiscordChannelID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.RemindAt,
+			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGuildSettings = `-- name: GetGuildSettings :one
+SELECT guild_id, timezone, delivery_channel_id, list_show_others, max_pending_per_user FROM guild_settings
+WHERE guild_id = $1
+`
+
+func (q *Queries) GetGuildSettings(ctx context.Context, guildID string) (GuildSetting, error) {
+	row := q.queryRow(ctx, q.getGuildSettingsStmt, getGuildSettings, guildID)
+	var i GuildSetting
+	err := row.Scan(
+		&i.GuildID,
+		&i.Timezone,
+		&i.DeliveryChannelID,
+		&i.ListShowOthers,
+		&i.MaxPendingPerUser,
+	)
+	return i, err
 }
 
 const getMemo = `-- name: GetMemo :one
-SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent FROM memos
+SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions FROM memos
 WHERE id = $1
 `
 
@@ -94,12 +363,23 @@ func (q *Queries) GetMemo(ctx context.Context, id int32) (Memo, error) {
 		&i.CreatedAt,
 		&i.RemindAt,
 		&i.Sent,
+		&i.Recurrence,
+		&i.Delivery,
+		&i.ClaimedBy,
+		&i.ClaimedUntil,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.Failed,
+		&i.GuildID,
+		&i.SourceMessageID,
+		&i.Mentions,
 	)
 	return i, err
 }
 
 const getPendingReminders = `-- name: GetPendingReminders :many
-SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent
+SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
 FROM memos
 WHERE sent = false AND remind_at <= $1
 ORDER BY remind_at
@@ -122,6 +402,17 @@ func (q *Queries) GetPendingReminders(ctx context.Context, remindAt time.Time) (
 			&i.CreatedAt,
 			&i.RemindAt,
 			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
 		); err != nil {
 			return nil, err
 		}
@@ -172,19 +463,24 @@ func (q *Queries) GetReminderCounts(ctx context.Context, discordUserID string) (
 }
 
 const getUser = `-- name: GetUser :one
-SELECT user_id, username, discord_channel_id FROM users
+SELECT user_id, username, discord_channel_id, timezone FROM users
 WHERE user_id = $1
 `
 
 func (q *Queries) GetUser(ctx context.Context, userID string) (User, error) {
 	row := q.queryRow(ctx, q.getUserStmt, getUser, userID)
 	var i User
-	err := row.Scan(&i.UserID, &i.Username, &i.DiscordChannelID)
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.DiscordChannelID,
+		&i.Timezone,
+	)
 	return i, err
 }
 
 const listAllPendingMemosInChannel = `-- name: ListAllPendingMemosInChannel :many
-SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent
+SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
 FROM memos
 WHERE discord_channel_id = $1
   AND remind_at > NOW()
@@ -209,6 +505,17 @@ func (q *Queries) ListAllPendingMemosInChannel(ctx context.Context, discordChann
 			&i.CreatedAt,
 			&i.RemindAt,
 			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
 		); err != nil {
 			return nil, err
 		}
@@ -224,7 +531,7 @@ func (q *Queries) ListAllPendingMemosInChannel(ctx context.Context, discordChann
 }
 
 const listPendingMemos = `-- name: ListPendingMemos :many
-SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent FROM memos
+SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions FROM memos
 WHERE discord_user_id = $1 AND discord_channel_id = $2 AND sent = false
 ORDER BY remind_at
 `
@@ -251,6 +558,17 @@ func (q *Queries) ListPendingMemos(ctx context.Context, arg ListPendingMemosPara
 			&i.CreatedAt,
 			&i.RemindAt,
 			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
 		); err != nil {
 			return nil, err
 		}
@@ -265,9 +583,128 @@ func (q *Queries) ListPendingMemos(ctx context.Context, arg ListPendingMemosPara
 	return items, nil
 }
 
+const listUpcomingReminders = `-- name: ListUpcomingReminders :many
+SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
+FROM memos
+WHERE sent = false AND failed = false
+ORDER BY remind_at
+LIMIT $1
+`
+
+func (q *Queries) ListUpcomingReminders(ctx context.Context, limit int32) ([]Memo, error) {
+	rows, err := q.query(ctx, q.listUpcomingRemindersStmt, listUpcomingReminders, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Memo
+	for rows.Next() {
+		var i Memo
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUserID,
+			&i.DiscordChannelID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.RemindAt,
+			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserPendingMemos = `-- name: ListUserPendingMemos :many
+SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions FROM memos
+WHERE discord_user_id = $1 AND sent = false
+ORDER BY remind_at
+`
+
+func (q *Queries) ListUserPendingMemos(ctx context.Context, discordUserID string) ([]Memo, error) {
+	rows, err := q.query(ctx, q.listUserPendingMemosStmt, listUserPendingMemos, discordUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Memo
+	for rows.Next() {
+		var i Memo
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUserID,
+			&i.DiscordChannelID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.RemindAt,
+			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markClaimedMemoAsSent = `-- name: MarkClaimedMemoAsSent :execrows
+UPDATE memos
+SET sent = true, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
+WHERE id = $1 AND claimed_by = $2
+`
+
+type MarkClaimedMemoAsSentParams struct {
+	ID        int32          `json:"id"`
+	ClaimedBy sql.NullString `json:"claimed_by"`
+}
+
+func (q *Queries) MarkClaimedMemoAsSent(ctx context.Context, arg MarkClaimedMemoAsSentParams) (int64, error) {
+	result, err := q.exec(ctx, q.markClaimedMemoAsSentStmt, markClaimedMemoAsSent, arg.ID, arg.ClaimedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const markMemoAsSent = `-- name: MarkMemoAsSent :exec
 UPDATE memos
-SET sent = true
+SET sent = true, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
 WHERE id = $1
 `
 
@@ -276,6 +713,280 @@ func (q *Queries) MarkMemoAsSent(ctx context.Context, id int32) error {
 	return err
 }
 
+const reassignMemo = `-- name: ReassignMemo :one
+UPDATE memos
+SET discord_user_id = $2
+WHERE id = $1 AND sent = false
+RETURNING id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
+`
+
+type ReassignMemoParams struct {
+	ID            int32  `json:"id"`
+	DiscordUserID string `json:"discord_user_id"`
+}
+
+func (q *Queries) ReassignMemo(ctx context.Context, arg ReassignMemoParams) (Memo, error) {
+	row := q.queryRow(ctx, q.reassignMemoStmt, reassignMemo, arg.ID, arg.DiscordUserID)
+	var i Memo
+	err := row.Scan(
+		&i.ID,
+		&i.DiscordUserID,
+		&i.DiscordChannelID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.RemindAt,
+		&i.Sent,
+		&i.Recurrence,
+		&i.Delivery,
+		&i.ClaimedBy,
+		&i.ClaimedUntil,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.Failed,
+		&i.GuildID,
+		&i.SourceMessageID,
+		&i.Mentions,
+	)
+	return i, err
+}
+
+const recordDeliveryFailure = `-- name: RecordDeliveryFailure :execrows
+UPDATE memos
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, failed = $4,
+    claimed_by = NULL, claimed_until = NULL
+WHERE id = $1 AND claimed_by = $5
+`
+
+type RecordDeliveryFailureParams struct {
+	ID            int32          `json:"id"`
+	LastError     sql.NullString `json:"last_error"`
+	NextAttemptAt sql.NullTime   `json:"next_attempt_at"`
+	Failed        bool           `json:"failed"`
+	ClaimedBy     sql.NullString `json:"claimed_by"`
+}
+
+func (q *Queries) RecordDeliveryFailure(ctx context.Context, arg RecordDeliveryFailureParams) (int64, error) {
+	result, err := q.exec(ctx, q.recordDeliveryFailureStmt, recordDeliveryFailure,
+		arg.ID,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.Failed,
+		arg.ClaimedBy,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordModeration = `-- name: RecordModeration :exec
+INSERT INTO memo_moderation_log (memo_id, guild_id, action, moderator_id, owner_id, new_owner_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type RecordModerationParams struct {
+	MemoID      int32          `json:"memo_id"`
+	GuildID     sql.NullString `json:"guild_id"`
+	Action      string         `json:"action"`
+	ModeratorID string         `json:"moderator_id"`
+	OwnerID     string         `json:"owner_id"`
+	NewOwnerID  sql.NullString `json:"new_owner_id"`
+}
+
+func (q *Queries) RecordModeration(ctx context.Context, arg RecordModerationParams) error {
+	_, err := q.exec(ctx, q.recordModerationStmt, recordModeration,
+		arg.MemoID,
+		arg.GuildID,
+		arg.Action,
+		arg.ModeratorID,
+		arg.OwnerID,
+		arg.NewOwnerID,
+	)
+	return err
+}
+
+const renewClaim = `-- name: RenewClaim :execrows
+UPDATE memos
+SET claimed_until = $3
+WHERE id = $1 AND claimed_by = $2 AND sent = false
+`
+
+type RenewClaimParams struct {
+	ID           int32          `json:"id"`
+	ClaimedBy    sql.NullString `json:"claimed_by"`
+	ClaimedUntil sql.NullTime   `json:"claimed_until"`
+}
+
+// Extends a worker's lease right before it sends a memo. No rows means the
+// lease expired and another worker may have reclaimed the memo.
+func (q *Queries) RenewClaim(ctx context.Context, arg RenewClaimParams) (int64, error) {
+	result, err := q.exec(ctx, q.renewClaimStmt, renewClaim, arg.ID, arg.ClaimedBy, arg.ClaimedUntil)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rescheduleMemo = `-- name: RescheduleMemo :execrows
+UPDATE memos
+SET remind_at = $2, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
+WHERE id = $1 AND claimed_by = $3
+`
+
+type RescheduleMemoParams struct {
+	ID        int32          `json:"id"`
+	RemindAt  time.Time      `json:"remind_at"`
+	ClaimedBy sql.NullString `json:"claimed_by"`
+}
+
+func (q *Queries) RescheduleMemo(ctx context.Context, arg RescheduleMemoParams) (int64, error) {
+	result, err := q.exec(ctx, q.rescheduleMemoStmt, rescheduleMemo, arg.ID, arg.RemindAt, arg.ClaimedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchPendingMemos = `-- name: SearchPendingMemos :many
+SELECT id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions FROM memos
+WHERE discord_user_id = $1
+  AND sent = false
+  AND (content ILIKE ($2 || '%') ESCAPE '\' OR CAST(id AS TEXT) LIKE ($2 || '%') ESCAPE '\')
+ORDER BY remind_at
+LIMIT $3
+`
+
+type SearchPendingMemosParams struct {
+	DiscordUserID string         `json:"discord_user_id"`
+	Prefix        sql.NullString `json:"prefix"`
+	MaxResults    int32          `json:"max_results"`
+}
+
+func (q *Queries) SearchPendingMemos(ctx context.Context, arg SearchPendingMemosParams) ([]Memo, error) {
+	rows, err := q.query(ctx, q.searchPendingMemosStmt, searchPendingMemos, arg.DiscordUserID, arg.Prefix, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Memo
+	for rows.Next() {
+		var i Memo
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUserID,
+			&i.DiscordChannelID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.RemindAt,
+			&i.Sent,
+			&i.Recurrence,
+			&i.Delivery,
+			&i.ClaimedBy,
+			&i.ClaimedUntil,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.Failed,
+			&i.GuildID,
+			&i.SourceMessageID,
+			&i.Mentions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserTimezone = `-- name: SetUserTimezone :exec
+INSERT INTO users (user_id, username, timezone)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET username = EXCLUDED.username, timezone = EXCLUDED.timezone
+`
+
+type SetUserTimezoneParams struct {
+	UserID   string         `json:"user_id"`
+	Username string         `json:"username"`
+	Timezone sql.NullString `json:"timezone"`
+}
+
+func (q *Queries) SetUserTimezone(ctx context.Context, arg SetUserTimezoneParams) error {
+	_, err := q.exec(ctx, q.setUserTimezoneStmt, setUserTimezone, arg.UserID, arg.Username, arg.Timezone)
+	return err
+}
+
+const snoozeMemo = `-- name: SnoozeMemo :exec
+UPDATE memos
+SET remind_at = $2, sent = false, claimed_by = NULL, claimed_until = NULL
+WHERE id = $1
+`
+
+type SnoozeMemoParams struct {
+	ID       int32     `json:"id"`
+	RemindAt time.Time `json:"remind_at"`
+}
+
+func (q *Queries) SnoozeMemo(ctx context.Context, arg SnoozeMemoParams) error {
+	_, err := q.exec(ctx, q.snoozeMemoStmt, snoozeMemo, arg.ID, arg.RemindAt)
+	return err
+}
+
+const updateMemo = `-- name: UpdateMemo :one
+UPDATE memos
+SET content = $3, remind_at = $4, failed = false,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL,
+    claimed_by = NULL, claimed_until = NULL
+WHERE id = $1 AND discord_user_id = $2 AND sent = false
+RETURNING id, discord_user_id, discord_channel_id, content, created_at, remind_at, sent, recurrence, delivery, claimed_by, claimed_until, attempts, last_error, next_attempt_at, failed, guild_id, source_message_id, mentions
+`
+
+type UpdateMemoParams struct {
+	ID            int32     `json:"id"`
+	DiscordUserID string    `json:"discord_user_id"`
+	Content       string    `json:"content"`
+	RemindAt      time.Time `json:"remind_at"`
+}
+
+func (q *Queries) UpdateMemo(ctx context.Context, arg UpdateMemoParams) (Memo, error) {
+	row := q.queryRow(ctx, q.updateMemoStmt, updateMemo,
+		arg.ID,
+		arg.DiscordUserID,
+		arg.Content,
+		arg.RemindAt,
+	)
+	var i Memo
+	err := row.Scan(
+		&i.ID,
+		&i.DiscordUserID,
+		&i.DiscordChannelID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.RemindAt,
+		&i.Sent,
+		&i.Recurrence,
+		&i.Delivery,
+		&i.ClaimedBy,
+		&i.ClaimedUntil,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.Failed,
+		&i.GuildID,
+		&i.SourceMessageID,
+		&i.Mentions,
+	)
+	return i, err
+}
+
 const updateUserDiscordChannel = `-- name: UpdateUserDiscordChannel :exec
 UPDATE users
 SET discord_channel_id = $2
@@ -291,3 +1002,30 @@ func (q *Queries) UpdateUserDiscordChannel(ctx context.Context, arg UpdateUserDi
 	_, err := q.exec(ctx, q.updateUserDiscordChannelStmt, updateUserDiscordChannel, arg.UserID, arg.DiscordChannelID)
 	return err
 }
+
+const upsertGuildSettings = `-- name: UpsertGuildSettings :exec
+INSERT INTO guild_settings (guild_id, timezone, delivery_channel_id, list_show_others, max_pending_per_user)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (guild_id) DO UPDATE
+SET timezone = EXCLUDED.timezone, delivery_channel_id = EXCLUDED.delivery_channel_id,
+    list_show_others = EXCLUDED.list_show_others, max_pending_per_user = EXCLUDED.max_pending_per_user
+`
+
+type UpsertGuildSettingsParams struct {
+	GuildID           string         `json:"guild_id"`
+	Timezone          sql.NullString `json:"timezone"`
+	DeliveryChannelID sql.NullString `json:"delivery_channel_id"`
+	ListShowOthers    bool           `json:"list_show_others"`
+	MaxPendingPerUser int32          `json:"max_pending_per_user"`
+}
+
+func (q *Queries) UpsertGuildSettings(ctx context.Context, arg UpsertGuildSettingsParams) error {
+	_, err := q.exec(ctx, q.upsertGuildSettingsStmt, upsertGuildSettings,
+		arg.GuildID,
+		arg.Timezone,
+		arg.DeliveryChannelID,
+		arg.ListShowOthers,
+		arg.MaxPendingPerUser,
+	)
+	return err
+}
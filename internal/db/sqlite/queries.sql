@@ -0,0 +1,183 @@
+-- name: CreateUser :one
+INSERT INTO users (user_id, username, discord_channel_id)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE user_id = ?;
+
+-- name: UpdateUserDiscordChannel :exec
+UPDATE users
+SET discord_channel_id = ?
+WHERE user_id = ?;
+
+-- name: SetUserTimezone :exec
+INSERT INTO users (user_id, username, timezone)
+VALUES (?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE
+SET username = excluded.username, timezone = excluded.timezone;
+
+-- name: CreateMemo :one
+INSERT INTO memos (discord_user_id, discord_channel_id, content, remind_at, recurrence, delivery, guild_id, source_message_id, mentions)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListPendingMemos :many
+SELECT * FROM memos
+WHERE discord_user_id = ? AND discord_channel_id = ? AND sent = false
+ORDER BY remind_at;
+
+-- name: ListUserPendingMemos :many
+SELECT * FROM memos
+WHERE discord_user_id = ? AND sent = false
+ORDER BY remind_at;
+
+-- name: SearchPendingMemos :many
+-- LIKE is case-insensitive for ASCII in SQLite, matching ILIKE in Postgres
+SELECT * FROM memos
+WHERE discord_user_id = sqlc.arg(discord_user_id)
+  AND sent = false
+  AND (content LIKE (sqlc.arg(prefix) || '%') ESCAPE '\' OR CAST(id AS TEXT) LIKE (sqlc.arg(prefix) || '%') ESCAPE '\')
+ORDER BY remind_at
+LIMIT sqlc.arg(max_results);
+
+-- name: GetReminderCounts :many
+SELECT discord_channel_id, COUNT(*) as count
+FROM memos
+WHERE discord_user_id = ? AND sent = false
+GROUP BY discord_channel_id;
+
+-- name: GetPendingReminders :many
+SELECT *
+FROM memos
+WHERE sent = false AND remind_at <= ?
+ORDER BY remind_at;
+
+-- name: ClaimPendingReminders :many
+-- Leases due memos to one worker. SQLite serializes writers, so the UPDATE
+-- alone keeps two instances sharing a database file from claiming the same memo.
+UPDATE memos
+SET claimed_by = sqlc.arg(worker_id), claimed_until = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT id
+    FROM memos
+    WHERE sent = false
+      AND failed = false
+      AND remind_at <= sqlc.arg(now)
+      AND (claimed_until IS NULL OR claimed_until < sqlc.arg(now))
+      AND (next_attempt_at IS NULL OR next_attempt_at <= sqlc.arg(now))
+    ORDER BY remind_at
+    LIMIT sqlc.arg(batch_size)
+)
+RETURNING *;
+
+-- name: RenewClaim :execrows
+-- Extends a worker's lease right before it sends a memo. No rows means the
+-- lease expired and another worker may have reclaimed the memo.
+UPDATE memos
+SET claimed_until = ?
+WHERE id = ? AND claimed_by = ? AND sent = false;
+
+-- name: RecordDeliveryFailure :execrows
+UPDATE memos
+SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?, failed = ?,
+    claimed_by = NULL, claimed_until = NULL
+WHERE id = ? AND claimed_by = ?;
+
+-- name: ListUpcomingReminders :many
+SELECT *
+FROM memos
+WHERE sent = false AND failed = false
+ORDER BY remind_at
+LIMIT ?;
+
+-- name: MarkMemoAsSent :exec
+UPDATE memos
+SET sent = true, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
+WHERE id = ?;
+
+-- name: MarkClaimedMemoAsSent :execrows
+UPDATE memos
+SET sent = true, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
+WHERE id = ? AND claimed_by = ?;
+
+-- name: RescheduleMemo :execrows
+UPDATE memos
+SET remind_at = ?, claimed_by = NULL, claimed_until = NULL,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL
+WHERE id = ? AND claimed_by = ?;
+
+-- name: SnoozeMemo :exec
+UPDATE memos
+SET remind_at = ?, sent = false, claimed_by = NULL, claimed_until = NULL
+WHERE id = ?;
+
+-- name: UpdateMemo :one
+UPDATE memos
+SET content = ?, remind_at = ?, failed = false,
+    attempts = 0, last_error = NULL, next_attempt_at = NULL,
+    claimed_by = NULL, claimed_until = NULL
+WHERE id = ? AND discord_user_id = ? AND sent = false
+RETURNING *;
+
+-- name: DeleteMemo :execrows
+DELETE FROM memos
+WHERE id = ? AND discord_user_id = ?;
+
+-- name: ListAllPendingMemosInChannel :many
+SELECT *
+FROM memos
+WHERE discord_channel_id = ?
+  AND remind_at > strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+  AND sent = false
+ORDER BY remind_at ASC;
+
+-- name: GetMemo :one
+SELECT * FROM memos
+WHERE id = ?;
+
+-- name: CountUserPendingMemos :one
+SELECT COUNT(*) FROM memos
+WHERE discord_user_id = ? AND sent = false AND failed = false;
+
+-- name: CountPendingMemosInChannel :one
+SELECT COUNT(*) FROM memos
+WHERE discord_channel_id = ? AND sent = false AND failed = false;
+
+-- name: CountUserPendingMemosInGuild :one
+SELECT COUNT(*) FROM memos
+WHERE discord_user_id = ? AND guild_id = ? AND sent = false AND failed = false;
+
+-- name: GetGuildSettings :one
+SELECT * FROM guild_settings
+WHERE guild_id = ?;
+
+-- name: UpsertGuildSettings :exec
+INSERT INTO guild_settings (guild_id, timezone, delivery_channel_id, list_show_others, max_pending_per_user)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (guild_id) DO UPDATE
+SET timezone = excluded.timezone, delivery_channel_id = excluded.delivery_channel_id,
+    list_show_others = excluded.list_show_others, max_pending_per_user = excluded.max_pending_per_user;
+
+-- name: ReassignMemo :one
+UPDATE memos
+SET discord_user_id = ?
+WHERE id = ? AND sent = false
+RETURNING *;
+
+-- name: DeletePendingMemosByUserInGuild :many
+DELETE FROM memos
+WHERE discord_user_id = ? AND guild_id = ? AND sent = false
+RETURNING *;
+
+-- name: DeletePendingLegacyMemosByUserInChannel :many
+DELETE FROM memos
+WHERE discord_user_id = ? AND discord_channel_id = ? AND guild_id IS NULL AND sent = false
+RETURNING *;
+
+-- name: RecordModeration :exec
+INSERT INTO memo_moderation_log (memo_id, guild_id, action, moderator_id, owner_id, new_owner_id)
+VALUES (?, ?, ?, ?, ?, ?);
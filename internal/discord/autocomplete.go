@@ -0,0 +1,148 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"memo-bot/internal/logging"
+	"memo-bot/internal/timeutil"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxAutocompleteChoices is the most choices Discord accepts in one autocomplete response
+	maxAutocompleteChoices = 25
+	// maxChoiceLength is Discord's limit for a choice's name and string value
+	maxChoiceLength = 100
+)
+
+// whenExamples are suggested while the "when" option is still empty
+var whenExamples = []string{
+	"in 30 minutes",
+	"in 2 hours",
+	"today at 5pm",
+	"tomorrow at 9am",
+	"next monday at 10:00",
+}
+
+// handleAutocomplete suggests values for options marked with Autocomplete
+func (c *Client) handleAutocomplete(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	focused := focusedOption(data.Options)
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	if focused != nil {
+		value := strings.TrimSpace(fmt.Sprint(focused.Value))
+		userID := interactionUserID(i)
+
+		switch {
+		case data.Name == "timezone" && focused.Name == "name",
+			data.Name == "config" && focused.Name == settingTimezone:
+			choices = timezoneChoices(value)
+		case (data.Name == "delete" || data.Name == "edit") && focused.Name == "id":
+			choices = c.memoIDChoices(ctx, userID, i.GuildID, value)
+		case (data.Name == "memo" || data.Name == "edit") && focused.Name == "when":
+			choices = c.whenChoices(ctx, userID, i.GuildID, value)
+		}
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error responding to autocomplete", logging.KeyError, err)
+	}
+}
+
+// focusedOption finds the option being typed, descending into subcommands
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, opt := range options {
+		if opt.Focused {
+			return opt
+		}
+		if found := focusedOption(opt.Options); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func timezoneChoices(query string) []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, name := range timeutil.SearchTimezones(query, maxAutocompleteChoices) {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  name,
+			Value: name,
+		})
+	}
+	return choices
+}
+
+// memoIDChoices lists the caller's pending memos whose content or ID starts with prefix
+func (c *Client) memoIDChoices(ctx context.Context, userID, guildID, prefix string) []*discordgo.ApplicationCommandOptionChoice {
+	memos, err := c.service.SearchPendingMemos(ctx, userID, prefix, maxAutocompleteChoices)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error searching memos for autocomplete", logging.KeyError, err)
+		return nil
+	}
+
+	loc := c.userLocation(ctx, userID, guildID)
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, memo := range memos {
+		name := fmt.Sprintf("#%d · %s · %s",
+			memo.ID,
+			memo.RemindAt.In(loc).Format("Jan 2 15:04"),
+			strings.Join(strings.Fields(memo.Content), " "))
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncate(name, maxChoiceLength),
+			Value: memo.ID,
+		})
+	}
+	return choices
+}
+
+// whenChoices previews what the partially typed time resolves to
+func (c *Client) whenChoices(ctx context.Context, userID, guildID, input string) []*discordgo.ApplicationCommandOptionChoice {
+	inputs := []string{input}
+	if input == "" {
+		inputs = whenExamples
+	}
+
+	tz := c.userTimezone(ctx, userID, guildID)
+	loc := c.userLocation(ctx, userID, guildID)
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, in := range inputs {
+		in = truncate(in, maxChoiceLength)
+		remindAt, err := timeutil.ParseTime(in, tz, c.clock)
+
+		var name string
+		switch {
+		case err != nil:
+			name = fmt.Sprintf("%s → (not understood yet)", in)
+		case remindAt.Before(c.clock.Now()):
+			name = fmt.Sprintf("%s → %s (in the past)", in, remindAt.In(loc).Format("Mon, Jan 2 15:04 MST"))
+		default:
+			name = fmt.Sprintf("%s → %s", in, remindAt.In(loc).Format("Mon, Jan 2 15:04 MST"))
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncate(name, maxChoiceLength),
+			Value: in,
+		})
+	}
+	return choices
+}
+
+// truncate shortens s to at most max runes, marking the cut with an ellipsis
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -2,12 +2,18 @@ package discord
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"strings"
 	"time"
 
+	"memo-bot/internal/clock"
 	"memo-bot/internal/db"
+	"memo-bot/internal/logging"
+	"memo-bot/internal/ratelimit"
+	"memo-bot/internal/recurrence"
 	"memo-bot/internal/service"
 	"memo-bot/internal/timeutil"
 
@@ -22,54 +28,161 @@ var commands = []*discordgo.ApplicationCommand{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "content",
-				Description: "What to remind you about",
-				Required:    true,
+				Description: "What to remind you about (leave empty to write a multi-line memo)",
+				Required:    false,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "when",
+				Description:  "When to remind you ('in 2 hours', 'tomorrow at 3pm', 'next monday at 15:00', or '2024-03-07 15:30')",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "repeat",
+				Description: "Repeat the memo ('daily', 'weekdays', 'weekly', 'monthly', or an RRULE like 'FREQ=WEEKLY;BYDAY=MO')",
+				Required:    false,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
-				Name:        "when",
-				Description: "When to remind you ('in 2 hours', 'tomorrow at 3pm', 'next monday at 15:00', or '2024-03-07 15:30')",
-				Required:    true,
+				Name:        "deliver",
+				Description: "Where to send the reminder (default: this channel)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "This channel", Value: service.DeliveryChannel},
+					{Name: "Direct message", Value: service.DeliveryDM},
+					{Name: "Both", Value: service.DeliveryBoth},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "target_user",
+				Description: "Someone else to ping with the reminder",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionRole,
+				Name:        "target_role",
+				Description: "A role to ping with the reminder, e.g. @oncall",
+				Required:    false,
 			},
 		},
 	},
 	{
 		Name:        "list",
 		Description: "Show all your pending memos",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "show",
+				Description: "Which memos to show (default: yours in this channel)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Mine in this channel", Value: listScopeMine},
+					{Name: "Everyone in this channel", Value: listScopeChannel},
+					{Name: "Mine in all channels", Value: listScopeAll},
+				},
+			},
+		},
 	},
 	{
 		Name:        "delete",
 		Description: "Delete a specific memo",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "id",
-				Description: "The ID of the memo to delete",
-				Required:    true,
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "id",
+				Description:  "The ID of the memo to delete",
+				Required:     true,
+				Autocomplete: true,
 			},
 		},
 	},
+	{
+		Name:        "edit",
+		Description: "Change the content or time of a pending memo",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionInteger,
+				Name:         "id",
+				Description:  "The ID of the memo to edit",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "content",
+				Description: "New memo content",
+				Required:    false,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "when",
+				Description:  "New reminder time ('in 2 hours', 'tomorrow at 3pm', or '2024-03-07 15:30')",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	},
+	{
+		Name:        "timezone",
+		Description: "Manage your personal timezone",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set",
+				Description: "Set the timezone used for your memos",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "name",
+						Description:  "IANA timezone name, e.g. Europe/Berlin",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Show the timezone used for your memos",
+			},
+		},
+	},
+	configCommand,
+	reassignCommand,
+	purgeCommand,
+	{
+		Name: remindMessageCommand,
+		Type: discordgo.MessageApplicationCommand,
+	},
 }
 
 // Client represents a Discord client that handles all Discord-related operations
 type Client struct {
-	session  *discordgo.Session
+	// gateway is the websocket connection; nil for clients built on a fake session
+	gateway  *discordgo.Session
+	session  Session
 	service  *service.MemoService
 	timezone string
+	clock    clock.Clock
+	scope    CommandScope
+	// memoLimiter throttles memo creation per user; nil means no limit
+	memoLimiter *ratelimit.Limiter
+	// appID is set once commands are registered
+	appID string
 }
 
 // NewClient creates a new Discord client
-func NewClient(botToken string, service *service.MemoService, timezone string) (*Client, error) {
+func NewClient(botToken string, service *service.MemoService, timezone string, clk clock.Clock) (*Client, error) {
 	session, err := discordgo.New("Bot " + botToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Discord session: %w", err)
 	}
 
-	client := &Client{
-		session:  session,
-		service:  service,
-		timezone: timezone,
-	}
+	client := NewClientWithSession(session, service, timezone, clk)
+	client.gateway = session
 
 	// Set up command handlers
 	session.AddHandler(client.handleInteraction)
@@ -77,41 +190,103 @@ func NewClient(botToken string, service *service.MemoService, timezone string) (
 	return client, nil
 }
 
+// NewClientWithSession creates a client that talks to Discord through session
+// without opening a gateway connection; feed it events with HandleInteraction
+func NewClientWithSession(session Session, service *service.MemoService, timezone string, clk clock.Clock) *Client {
+	return &Client{
+		session:  session,
+		service:  service,
+		timezone: timezone,
+		clock:    clk,
+	}
+}
+
 // Connect establishes a connection to Discord and registers slash commands
 func (c *Client) Connect() error {
-	if err := c.session.Open(); err != nil {
+	if c.gateway == nil {
+		return fmt.Errorf("failed to connect to Discord: client has no gateway session")
+	}
+	if err := c.gateway.Open(); err != nil {
 		return fmt.Errorf("failed to connect to Discord: %w", err)
 	}
 
-	// Register slash commands
-	for _, cmd := range commands {
-		_, err := c.session.ApplicationCommandCreate(c.session.State.User.ID, "", cmd)
-		if err != nil {
-			return fmt.Errorf("failed to create slash command %q: %w", cmd.Name, err)
-		}
-	}
+	return c.RegisterCommands(c.gateway.State.User.ID)
+}
 
-	return nil
+func (c *Client) handleInteraction(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+	c.HandleInteraction(i)
 }
 
-func (c *Client) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.Type != discordgo.InteractionApplicationCommand {
-		return
+// HandleInteraction routes an interaction to its handler and responds through
+// the client's session. Log lines of the interaction share a correlation ID.
+func (c *Client) HandleInteraction(i *discordgo.InteractionCreate) {
+	logger := slog.With(
+		logging.KeyCorrelationID, logging.NewID(),
+		logging.KeyInteractionID, i.ID,
+		logging.KeyUserID, interactionUserID(i),
+		logging.KeyGuildID, i.GuildID,
+		logging.KeyChannelID, i.ChannelID,
+	)
+	ctx := logging.WithLogger(context.Background(), logger)
+	logger.Debug("Handling interaction", "type", i.Type.String())
+
+	s := c.session
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if i.ApplicationCommandData().CommandType == discordgo.MessageApplicationCommand {
+			c.handleMessageCommand(ctx, s, i)
+			return
+		}
+		c.handleCommand(ctx, s, i)
+	case discordgo.InteractionMessageComponent:
+		c.handleComponent(ctx, s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		c.handleAutocomplete(ctx, s, i)
+	case discordgo.InteractionModalSubmit:
+		c.handleModalSubmit(ctx, s, i)
 	}
+}
 
+func (c *Client) handleCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
 	data := i.ApplicationCommandData()
 
 	var response string
 	var err error
 	var isEphemeral bool = true // Set default to ephemeral for all commands
 
+	// /list replies with embeds and navigation buttons instead of plain text
+	if data.Name == "list" {
+		c.handleListCommand(ctx, s, i)
+		return
+	}
+
+	if data.Name == "memo" {
+		if err := c.checkMemoRateLimit(i); err != nil {
+			c.respondEphemeral(ctx, s, i, fmt.Sprintf("❌ %s", err))
+			return
+		}
+		// /memo without content or time opens a modal with a multi-line editor
+		if req, err := memoRequestFromCommand(i); err == nil && (req.content == "" || req.when == "") {
+			c.openMemoModal(ctx, s, i, req)
+			return
+		}
+	}
+
 	switch data.Name {
 	case "memo":
-		response, err = c.handleMemoCommand(s, i)
-	case "list":
-		response, err = c.handleListCommand(s, i)
+		response, err = c.handleMemoCommand(ctx, s, i)
 	case "delete":
-		response, err = c.handleDeleteCommand(s, i)
+		response, err = c.handleDeleteCommand(ctx, s, i)
+	case "edit":
+		response, err = c.handleEditCommand(ctx, s, i)
+	case "timezone":
+		response, err = c.handleTimezoneCommand(ctx, s, i)
+	case "config":
+		response, err = c.handleConfigCommand(ctx, s, i)
+	case "reassign":
+		response, err = c.handleReassignCommand(ctx, s, i)
+	case "purge":
+		response, err = c.handlePurgeCommand(ctx, s, i)
 	}
 
 	if err != nil {
@@ -131,222 +306,380 @@ func (c *Client) handleInteraction(s *discordgo.Session, i *discordgo.Interactio
 		},
 	})
 	if err != nil {
-		log.Printf("Error responding to interaction: %v", err)
+		logging.FromContext(ctx).Error("Error responding to interaction", logging.KeyError, err)
 	}
 }
 
-func (c *Client) handleMemoCommand(s *discordgo.Session, i *discordgo.InteractionCreate) (string, error) {
-	options := i.ApplicationCommandData().Options
-	content := options[0].StringValue()
-	timeStr := options[1].StringValue()
+// optionMap indexes command options by name so optional options can be looked up
+func optionMap(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]*discordgo.ApplicationCommandInteractionDataOption {
+	m := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		m[opt.Name] = opt
+	}
+	return m
+}
 
-	// Parse relative and absolute time formats using timeutil package
-	remindAt, err := timeutil.ParseTime(timeStr, c.timezone)
+// memoRequest is the raw user input for a new memo, from /memo options or the memo modal
+type memoRequest struct {
+	content  string
+	when     string
+	repeat   string
+	deliver  string
+	mentions []service.Mention
+}
+
+func (c *Client) handleMemoCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) (string, error) {
+	req, err := memoRequestFromCommand(i)
 	if err != nil {
-		return "", fmt.Errorf("invalid time format (case-insensitive). Examples:\n- today at 3pm\n- tomorrow at 3pm\n- in 2 hours\n- next monday at 15:00\n- 2024-03-07 15:30")
+		return "", err
 	}
+	return c.createMemo(ctx, i, req)
+}
 
-	// Check if the time is in the past
-	if remindAt.Before(time.Now()) {
-		return "", fmt.Errorf("memo time must be in the future")
+// memoRequestFromCommand reads the /memo options and checks that the caller
+// may ping the chosen targets
+func memoRequestFromCommand(i *discordgo.InteractionCreate) (memoRequest, error) {
+	data := i.ApplicationCommandData()
+	options := optionMap(data.Options)
+	var req memoRequest
+	if opt, ok := options["content"]; ok {
+		req.content = opt.StringValue()
+	}
+	if opt, ok := options["when"]; ok {
+		req.when = opt.StringValue()
+	}
+	if opt, ok := options["repeat"]; ok {
+		req.repeat = opt.StringValue()
+	}
+	if opt, ok := options["deliver"]; ok {
+		req.deliver = opt.StringValue()
 	}
+	if opt, ok := options["target_user"]; ok {
+		req.mentions = append(req.mentions, service.Mention{Kind: service.MentionUser, ID: opt.UserValue(nil).ID})
+	}
+	if opt, ok := options["target_role"]; ok {
+		role := opt.RoleValue(nil, "")
+		if err := checkRoleMention(i, data.Resolved, role.ID); err != nil {
+			return memoRequest{}, err
+		}
+		req.mentions = append(req.mentions, service.Mention{Kind: service.MentionRole, ID: role.ID})
+	}
+	return req, nil
+}
 
-	ctx := context.Background()
-	err = c.service.CreateMemo(ctx, i.Member.User.ID, i.ChannelID, content, remindAt)
-	if err != nil {
-		return "", err
+// checkRoleMention stops members from pinging roles they couldn't mention
+// themselves: the role must be mentionable, or the member must have the
+// Mention @everyone permission. @everyone itself can't be a target.
+func checkRoleMention(i *discordgo.InteractionCreate, resolved *discordgo.ApplicationCommandInteractionDataResolved, roleID string) error {
+	if roleID == i.GuildID {
+		return fmt.Errorf("memos can't ping @everyone")
+	}
+	if i.Member != nil && i.Member.Permissions&discordgo.PermissionMentionEveryone != 0 {
+		return nil
+	}
+	if resolved != nil {
+		if role, ok := resolved.Roles[roleID]; ok && role.Mentionable {
+			return nil
+		}
 	}
+	return fmt.Errorf("you can't ping <@&%s>: the role isn't mentionable and you don't have the Mention @everyone permission", roleID)
+}
 
-	// Shorten content if it's too long
-	displayContent := content
-	if len(content) > 50 {
-		displayContent = content[:47] + "..."
+// createMemo validates a memo request and stores it
+func (c *Client) createMemo(ctx context.Context, i *discordgo.InteractionCreate, req memoRequest) (string, error) {
+	userID := interactionUserID(i)
+	content := strings.TrimSpace(req.content)
+	if content == "" {
+		return "", fmt.Errorf("memo content can't be empty")
 	}
 
-	// Load configured timezone
-	loc, err := time.LoadLocation(c.timezone)
+	var rule *recurrence.Rule
+	if strings.TrimSpace(req.repeat) != "" {
+		var err error
+		rule, err = recurrence.Parse(req.repeat)
+		if err != nil {
+			return "", fmt.Errorf("invalid repeat rule: %v. Examples:\n- daily\n- weekdays\n- weekly\n- FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", err)
+		}
+	}
+
+	// Parse relative and absolute time formats using timeutil package
+	remindAt, err := timeutil.ParseTime(req.when, c.userTimezone(ctx, userID, i.GuildID), c.clock)
 	if err != nil {
-		log.Printf("Error loading timezone: %v, falling back to Local", err)
-		loc = time.Local
+		return "", fmt.Errorf("invalid time format (case-insensitive). Examples:\n- today at 3pm\n- tomorrow at 3pm\n- in 2 hours\n- next monday at 15:00\n- 2024-03-07 15:30")
 	}
 
-	return fmt.Sprintf("✅ <@%s> created a memo: %s\n⏰ %s",
-		i.Member.User.ID,
-		displayContent,
-		remindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST")), nil
-}
+	// Check if the time is in the past
+	if remindAt.Before(c.clock.Now()) {
+		return "", fmt.Errorf("memo time must be in the future")
+	}
 
-func (c *Client) handleListCommand(s *discordgo.Session, i *discordgo.InteractionCreate) (string, error) {
-	ctx := context.Background()
+	opts := service.MemoOptions{
+		Recurrence: rule,
+		Delivery:   req.deliver,
+		GuildID:    i.GuildID,
+		Mentions:   req.mentions,
+	}
 
-	// Get personal memos for current channel
-	personalMemos, err := c.service.ListPendingMemos(ctx, i.Member.User.ID, i.ChannelID)
+	channelID, err := c.deliveryChannel(ctx, i)
 	if err != nil {
 		return "", err
 	}
 
-	// Get all memos in current channel
-	allChannelMemos, err := c.service.ListAllPendingMemosInChannel(ctx, i.ChannelID)
+	memo, err := c.service.CreateMemo(ctx, userID, channelID, content, remindAt, opts)
 	if err != nil {
 		return "", err
 	}
 
-	// Get counts across all channels for the user
-	counts, err := c.service.GetReminderCounts(ctx, i.Member.User.ID)
-	if err != nil {
-		return "", err
+	// Shorten content if it's too long
+	displayContent := truncate(content, 50)
+
+	loc := c.userLocation(ctx, userID, i.GuildID)
+
+	response := fmt.Sprintf("✅ <@%s> created a memo: %s\n⏰ %s",
+		userID,
+		displayContent,
+		memo.RemindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST"))
+	if rule != nil {
+		response += fmt.Sprintf("\n🔁 Repeats %s", rule.Describe())
+	}
+	switch opts.Delivery {
+	case service.DeliveryDM:
+		response += "\n📬 Delivered by direct message"
+	case service.DeliveryBoth:
+		response += "\n📬 Delivered here and by direct message"
+	}
+	if channelID != i.ChannelID && opts.Delivery != service.DeliveryDM {
+		response += fmt.Sprintf("\n📍 Will be posted in <#%s>", channelID)
 	}
+	if len(req.mentions) > 0 {
+		response += fmt.Sprintf("\n📣 Will ping %s", joinMentions(req.mentions))
+	}
+	return response, nil
+}
 
-	// Load configured timezone
-	loc, err := time.LoadLocation(c.timezone)
-	if err != nil {
-		log.Printf("Error loading timezone: %v, falling back to Local", err)
-		loc = time.Local
+// describeFailure returns a warning line for memos whose delivery failed, or an empty string
+func describeFailure(memo db.Memo) string {
+	if !memo.Failed {
+		return ""
 	}
+	return fmt.Sprintf("⚠️ **Delivery failed** after %d attempt(s): %s\nUse `/edit` to reschedule it or `/delete` to remove it\n",
+		memo.Attempts, memo.LastError.String)
+}
 
-	var response strings.Builder
+// describeRecurrence returns a "🔁 ..." line for recurring memos, or an empty string
+func describeRecurrence(memo db.Memo) string {
+	if !memo.Recurrence.Valid {
+		return ""
+	}
+	rule, err := recurrence.Parse(memo.Recurrence.String)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("🔁 Repeats %s\n", rule.Describe())
+}
 
-	response.WriteString(fmt.Sprintf("**Current channel** · %d memo(s) from all users\n", len(allChannelMemos)))
+func (c *Client) handleEditCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) (string, error) {
+	options := optionMap(i.ApplicationCommandData().Options)
+	memoID := options["id"].IntValue()
+	userID := interactionUserID(i)
 
-	// Show personal memos in current channel
-	response.WriteString("## Your memos in this channel\n")
-	if len(personalMemos) == 0 {
-		response.WriteString("You have no memos in this channel.\n")
-	} else {
-		for _, memo := range personalMemos {
-			response.WriteString(fmt.Sprintf("\n🔸 **Memo #%d**\n", memo.ID))
-			response.WriteString(fmt.Sprintf("⏰ %s\n", memo.RemindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST")))
-			response.WriteString(fmt.Sprintf("📌 %s\n", memo.Content))
-			response.WriteString("───────────────────\n")
-		}
+	var content *string
+	if opt, ok := options["content"]; ok {
+		value := opt.StringValue()
+		content = &value
 	}
 
-	// Show other users' memos in current channel
-	otherMemos := filterOtherUserMemos(allChannelMemos, i.Member.User.ID)
-	if len(otherMemos) > 0 {
-		response.WriteString("## Other's memos in this channel\n")
-		for _, memo := range otherMemos {
-			user, err := s.User(memo.DiscordUserID)
-			username := fmt.Sprintf("<@%s>", memo.DiscordUserID)
-			if err != nil || user == nil {
-				username = "Unknown User"
-			}
-			response.WriteString(fmt.Sprintf("\n🔹 **Memo #%d** by %s\n", memo.ID, username))
-			response.WriteString(fmt.Sprintf("⏰ %s\n", memo.RemindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST")))
-			response.WriteString(fmt.Sprintf("📌 %s\n", memo.Content))
-			response.WriteString("───────────────────\n")
+	var remindAt *time.Time
+	if opt, ok := options["when"]; ok {
+		parsed, err := timeutil.ParseTime(opt.StringValue(), c.userTimezone(ctx, userID, i.GuildID), c.clock)
+		if err != nil {
+			return "", fmt.Errorf("invalid time format (case-insensitive). Examples:\n- today at 3pm\n- tomorrow at 3pm\n- in 2 hours\n- next monday at 15:00\n- 2024-03-07 15:30")
 		}
+		remindAt = &parsed
 	}
 
-	// Build summary of other channels
-	totalMemos := 0
-	otherChannels := make(map[string][]channelInfo)
-	for _, count := range counts {
-		totalMemos += int(count.Count)
-		if count.DiscordChannelID != i.ChannelID {
-			channel, err := s.Channel(count.DiscordChannelID)
-			if err != nil || channel == nil {
-				continue
-			}
-			otherChannels[channel.GuildID] = append(otherChannels[channel.GuildID], channelInfo{
-				channel: channel,
-				count:   int(count.Count),
-			})
-		}
+	if content == nil && remindAt == nil {
+		return "", fmt.Errorf("nothing to change. Provide a new `content`, a new `when`, or both")
 	}
 
-	// Add summary section if there are memos in other channels
-	if len(otherChannels) > 0 {
-		response.WriteString("## Your memos in other channels / servers\n")
-		for guildID, channels := range otherChannels {
-			guild, err := s.Guild(guildID)
-			if err != nil || guild == nil {
-				continue
-			}
-			for _, ch := range channels {
-				response.WriteString(fmt.Sprintf("• <#%s>: %d memo(s)\n", ch.channel.ID, ch.count))
-			}
-		}
+	memo, err := c.service.UpdateMemo(ctx, int32(memoID), userID, content, remindAt)
+	if err != nil {
+		return "", err
 	}
 
-	// Add total count at the bottom
-	response.WriteString(fmt.Sprintf("\n📈 Total personal memos across all channels: %d", totalMemos))
+	loc := c.userLocation(ctx, userID, i.GuildID)
 
-	return response.String(), nil
+	return fmt.Sprintf("✅ Memo #%d updated\n⏰ %s\n%s📌 %s",
+		memo.ID,
+		memo.RemindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST"),
+		describeRecurrence(*memo),
+		memo.Content), nil
 }
 
-// filterOtherUserMemos returns memos that don't belong to the specified user
-func filterOtherUserMemos(memos []db.Memo, userID string) []db.Memo {
-	var otherMemos []db.Memo
-	for _, memo := range memos {
-		if memo.DiscordUserID != userID {
-			otherMemos = append(otherMemos, memo)
+// Close closes the Discord connection, first removing the slash commands if
+// the command scope asks for it
+func (c *Client) Close() error {
+	if c.scope.UnregisterOnClose {
+		if err := c.UnregisterCommands(); err != nil {
+			slog.Error("Error unregistering slash commands", logging.KeyError, err)
 		}
 	}
-	return otherMemos
-}
-
-type channelInfo struct {
-	channel *discordgo.Channel
-	count   int
+	if c.gateway == nil {
+		return nil
+	}
+	return c.gateway.Close()
 }
 
-func (c *Client) handleDeleteCommand(s *discordgo.Session, i *discordgo.InteractionCreate) (string, error) {
-	memoID := i.ApplicationCommandData().Options[0].IntValue()
-
-	ctx := context.Background()
+// maxEmbedDescriptionLength is Discord's limit for an embed description
+const maxEmbedDescriptionLength = 4096
+
+// SendReminder delivers a memo as an embed. Channel reminders ping the owner and
+// the memo's targets; nobody else can be pinged, whatever the content says. A
+// memo delivered both ways counts as delivered once its DM is sent.
+func (c *Client) SendReminder(ctx context.Context, memo db.Memo) error {
+	loc := c.userLocation(ctx, memo.DiscordUserID, memo.GuildID.String)
+	mentions := reminderMentions(memo)
+	embed := reminderEmbed(memo, loc)
+	content := joinMentions(mentions)
+
+	dmSent := false
+	if memo.Delivery == service.DeliveryDM || memo.Delivery == service.DeliveryBoth {
+		err := c.sendDirectMessage(memo.DiscordUserID, &discordgo.MessageSend{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			Components:      reminderComponents(memo.ID),
+			AllowedMentions: allowedMentions(nil),
+		})
+		dmSent = err == nil
+		if dmSent && memo.Delivery == service.DeliveryDM {
+			return nil
+		}
+		if err != nil {
+			// Users with DMs closed still get the reminder in the origin channel
+			logging.FromContext(ctx).Warn("Error sending memo by DM, falling back to channel", logging.KeyMemoID, memo.ID, logging.KeyError, err)
+			if memo.Delivery == service.DeliveryDM {
+				content += " I couldn't DM you, so here is your reminder:"
+			}
+		}
+	}
 
-	// First check if the memo exists and belongs to the user
-	memo, err := c.service.GetMemo(ctx, int32(memoID))
+	_, err := c.session.ChannelMessageSendComplex(memo.DiscordChannelID, &discordgo.MessageSend{
+		Content:         content,
+		Embeds:          []*discordgo.MessageEmbed{embed},
+		Components:      reminderComponents(memo.ID),
+		AllowedMentions: allowedMentions(mentions),
+	})
+	if err != nil && dmSent {
+		// The owner got the reminder; retrying would only DM them again
+		logging.FromContext(ctx).Warn("Error sending memo to channel, it was only delivered by DM", logging.KeyMemoID, memo.ID, logging.KeyError, err)
+		return nil
+	}
 	if err != nil {
-		return "", err
+		return fmt.Errorf("failed to send Discord message: %w", err)
 	}
+	return nil
+}
 
-	if memo.DiscordUserID != i.Member.User.ID {
-		return "", fmt.Errorf("memo #%d belongs to <@%s>. You can only delete your own memos", memoID, memo.DiscordUserID)
+// reminderEmbed shows the memo content with its schedule and source message
+func reminderEmbed(memo db.Memo, loc *time.Location) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       "🔔 Memo",
+		Description: truncate(memo.Content, maxEmbedDescriptionLength),
+		Color:       0x5865F2,
+		Timestamp:   memo.RemindAt.Format(time.RFC3339),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Memo #%d", memo.ID)},
+		Fields: []*discordgo.MessageEmbedField{{
+			Name:  "⏰ Scheduled for",
+			Value: memo.RemindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST"),
+		}},
+	}
+	if memo.Recurrence.Valid {
+		if rule, err := recurrence.Parse(memo.Recurrence.String); err == nil {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  "🔁 Repeats",
+				Value: rule.Describe(),
+			})
+		}
+	}
+	if memo.SourceMessageID.Valid {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "🔗 Source",
+			Value: fmt.Sprintf("[Jump to message](%s)", messageLink(memo)),
+		})
 	}
+	return embed
+}
 
-	err = c.service.DeleteMemo(ctx, int32(memoID), i.Member.User.ID)
-	if err != nil {
-		return "", fmt.Errorf("failed to delete memo: %v", err)
+// reminderMentions lists who a reminder pings: the owner, then the memo's targets
+func reminderMentions(memo db.Memo) []service.Mention {
+	mentions := []service.Mention{{Kind: service.MentionUser, ID: memo.DiscordUserID}}
+	for _, m := range service.ParseMentions(memo.Mentions.String) {
+		if m != mentions[0] {
+			mentions = append(mentions, m)
+		}
 	}
+	return mentions
+}
 
-	return "✅ Memo deleted successfully!", nil
+// joinMentions renders mentions as Discord markup separated by spaces
+func joinMentions(mentions []service.Mention) string {
+	parts := make([]string, len(mentions))
+	for i, m := range mentions {
+		parts[i] = m.String()
+	}
+	return strings.Join(parts, " ")
 }
 
-// Close closes the Discord connection
-func (c *Client) Close() error {
-	return c.session.Close()
+// allowedMentions only lets the given users and roles be pinged, so mention
+// markup inside the memo content, including @everyone, can't notify anyone
+func allowedMentions(mentions []service.Mention) *discordgo.MessageAllowedMentions {
+	allowed := &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}}
+	for _, m := range mentions {
+		switch m.Kind {
+		case service.MentionUser:
+			allowed.Users = append(allowed.Users, m.ID)
+		case service.MentionRole:
+			allowed.Roles = append(allowed.Roles, m.ID)
+		}
+	}
+	return allowed
 }
 
-// SendReminder sends a reminder message to Discord
-func (c *Client) SendReminder(memo db.Memo) error {
-	// Load configured timezone
-	loc, err := time.LoadLocation(c.timezone)
-	if err != nil {
-		log.Printf("Error loading timezone: %v, falling back to Local", err)
-		loc = time.Local
+// IsPermanentError reports whether a delivery error will not go away on retry,
+// e.g. the channel was deleted (404) or the bot lost access to it (403)
+func IsPermanentError(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return false
+	}
+	switch restErr.Response.StatusCode {
+	case http.StatusForbidden, http.StatusNotFound:
+		return true
 	}
+	return false
+}
 
-	// This message is public since it's the actual reminder
-	messageContent := fmt.Sprintf("🔔 **Memo** (scheduled for %s)\n```\n%s\n```",
-		memo.RemindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST"),
-		memo.Content)
-	_, err = c.session.ChannelMessageSend(memo.DiscordChannelID, messageContent)
+// sendDirectMessage opens (or reuses) the DM channel with a user and posts the message
+func (c *Client) sendDirectMessage(userID string, message *discordgo.MessageSend) error {
+	channel, err := c.session.UserChannelCreate(userID)
 	if err != nil {
-		return fmt.Errorf("failed to send Discord message: %w", err)
+		return fmt.Errorf("failed to open DM channel: %w", err)
+	}
+	if _, err := c.session.ChannelMessageSendComplex(channel.ID, message); err != nil {
+		return fmt.Errorf("failed to send DM: %w", err)
 	}
 	return nil
 }
 
 // IsConnected checks if the Discord client is connected
 func (c *Client) IsConnected() bool {
-	return c.session != nil && c.session.State != nil && c.session.State.SessionID != ""
+	return c.gateway != nil && c.gateway.State != nil && c.gateway.State.SessionID != ""
 }
 
 // AddMessageHandler adds a handler for incoming Discord messages
 func (c *Client) AddMessageHandler(handler func(*discordgo.Session, *discordgo.MessageCreate)) {
-	c.session.AddHandler(handler)
+	if c.gateway != nil {
+		c.gateway.AddHandler(handler)
+	}
 }
 
 // GetChannelName retrieves the channel name for a given channel ID
@@ -0,0 +1,842 @@
+package discord_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"memo-bot/internal/clock"
+	"memo-bot/internal/db/memdb"
+	"memo-bot/internal/discord"
+	"memo-bot/internal/discord/discordtest"
+	"memo-bot/internal/ratelimit"
+	"memo-bot/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var testStart = time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+var (
+	alice = discordtest.Invoker{UserID: "alice", GuildID: "guild-1", ChannelID: "channel-1"}
+	bob   = discordtest.Invoker{UserID: "bob", GuildID: "guild-1", ChannelID: "channel-1"}
+	// mod has Manage Messages in channel-1
+	mod = discordtest.Invoker{UserID: "mod", GuildID: "guild-1", ChannelID: "channel-1", Permissions: discordgo.PermissionManageMessages}
+	// aliceDM talks to the bot in a direct message
+	aliceDM = discordtest.Invoker{UserID: "alice", ChannelID: discordtest.DMChannelID("alice"), DM: true}
+)
+
+type testBot struct {
+	client  *discord.Client
+	session *discordtest.Session
+	service *service.MemoService
+	store   *memdb.Querier
+	clock   *clock.Fake
+}
+
+func newTestBot(t *testing.T) *testBot {
+	t.Helper()
+	clk := clock.NewFake(testStart)
+	store := memdb.New(clk)
+	svc := service.NewMemoService(store, clk)
+	session := discordtest.NewSession()
+	return &testBot{
+		client:  discord.NewClientWithSession(session, svc, "UTC", clk),
+		session: session,
+		service: svc,
+		store:   store,
+		clock:   clk,
+	}
+}
+
+// reply sends an interaction and returns the content of the bot's response
+func (b *testBot) reply(t *testing.T, i *discordgo.InteractionCreate) *discordgo.InteractionResponseData {
+	t.Helper()
+	before := len(b.session.Responses())
+	b.client.HandleInteraction(i)
+	responses := b.session.Responses()
+	if len(responses) != before+1 {
+		t.Fatalf("got %d responses to the interaction, want 1", len(responses)-before)
+	}
+	return responses[len(responses)-1].Response.Data
+}
+
+// deliverDue does what the reminder worker does on each scan
+func (b *testBot) deliverDue(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	memos, err := b.service.ClaimDueReminders(ctx, "worker-1", b.clock.Now(), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("ClaimDueReminders() error = %v", err)
+	}
+	for _, memo := range memos {
+		if err := b.client.SendReminder(ctx, memo); err != nil {
+			t.Fatalf("SendReminder(#%d) error = %v", memo.ID, err)
+		}
+		if err := b.service.CompleteReminder(ctx, memo, b.clock.Now(), time.UTC); err != nil {
+			t.Fatalf("CompleteReminder(#%d) error = %v", memo.ID, err)
+		}
+	}
+}
+
+func TestMemoIsDeliveredWhenDue(t *testing.T) {
+	bot := newTestBot(t)
+
+	data := bot.reply(t, alice.Command("memo",
+		discordtest.String("content", "stand-up"),
+		discordtest.String("when", "in 10 minutes"),
+	))
+	if !strings.HasPrefix(data.Content, "✅") || data.Flags != discordgo.MessageFlagsEphemeral {
+		t.Fatalf("/memo response = %q (flags %d)", data.Content, data.Flags)
+	}
+
+	bot.clock.Advance(9 * time.Minute)
+	bot.deliverDue(t)
+	if n := len(bot.session.Messages()); n != 0 {
+		t.Fatalf("sent %d messages before the memo was due", n)
+	}
+
+	bot.clock.Advance(time.Minute)
+	bot.deliverDue(t)
+	messages := bot.session.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(messages))
+	}
+	msg := messages[0]
+	if msg.ChannelID != "channel-1" || msg.Send.Content != "<@alice>" {
+		t.Fatalf("reminder = %q in %s, want a ping of the owner in channel-1", msg.Send.Content, msg.ChannelID)
+	}
+	if len(msg.Send.Embeds) != 1 || msg.Send.Embeds[0].Description != "stand-up" {
+		t.Fatalf("reminder embeds = %+v, want the memo content", msg.Send.Embeds)
+	}
+	if len(msg.Send.Components) != 1 {
+		t.Fatalf("reminder has %d component rows, want 1", len(msg.Send.Components))
+	}
+
+	// The memo is delivered once
+	bot.clock.Advance(time.Hour)
+	bot.deliverDue(t)
+	if n := len(bot.session.Messages()); n != 1 {
+		t.Fatalf("sent %d messages after redelivery scan, want 1", n)
+	}
+}
+
+// checkReminderUpdated checks a button click replaced the reminder's buttons
+// with the outcome and kept its embed
+func checkReminderUpdated(t *testing.T, data *discordgo.InteractionResponseData, reminder *discordgo.Message) {
+	t.Helper()
+	if len(data.Components) != 0 {
+		t.Errorf("updated reminder has %d component rows, want the buttons removed", len(data.Components))
+	}
+	if len(data.Embeds) != 1 || data.Embeds[0] != reminder.Embeds[0] {
+		t.Errorf("updated reminder embeds = %+v, want the reminder's embed kept", data.Embeds)
+	}
+}
+
+func TestDoneButton(t *testing.T) {
+	tests := []struct {
+		name       string
+		invoker    discordtest.Invoker
+		want       string
+		wantUpdate bool
+	}{
+		{name: "owner marks done", invoker: alice, want: "✅ Marked as done by <@alice>", wantUpdate: true},
+		{name: "other user is refused", invoker: bob, want: "❌ memo #1 belongs to <@alice>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := newTestBot(t)
+			bot.reply(t, alice.Command("memo",
+				discordtest.String("content", "stand-up"),
+				discordtest.String("when", "in 10 minutes"),
+			))
+			bot.clock.Advance(10 * time.Minute)
+			bot.deliverDue(t)
+
+			reminder := bot.session.Messages()[0].Message()
+			data := bot.reply(t, tt.invoker.Click(reminder, "done:1"))
+			if !strings.Contains(data.Content, tt.want) {
+				t.Fatalf("response = %q, want it to contain %q", data.Content, tt.want)
+			}
+			if tt.wantUpdate {
+				checkReminderUpdated(t, data, reminder)
+			}
+		})
+	}
+}
+
+func TestSnoozeButton(t *testing.T) {
+	tests := []struct {
+		key  string
+		want time.Duration
+	}{
+		{key: "10m", want: 10 * time.Minute},
+		{key: "1h", want: time.Hour},
+		{key: "tomorrow", want: 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			bot := newTestBot(t)
+			bot.reply(t, alice.Command("memo",
+				discordtest.String("content", "stand-up"),
+				discordtest.String("when", "in 10 minutes"),
+			))
+			bot.clock.Advance(10 * time.Minute)
+			bot.deliverDue(t)
+
+			reminder := bot.session.Messages()[0].Message()
+			data := bot.reply(t, alice.Click(reminder, "snooze:1:"+tt.key))
+			if !strings.Contains(data.Content, "💤 <@alice> snoozed until") {
+				t.Fatalf("response = %q, want the snooze", data.Content)
+			}
+			checkReminderUpdated(t, data, reminder)
+
+			if memo := bot.store.Memos()[0]; memo.Sent.Bool || !memo.RemindAt.Equal(bot.clock.Now().Add(tt.want)) {
+				t.Fatalf("snoozed memo sent = %v at %s, want pending at %s", memo.Sent.Bool, memo.RemindAt, bot.clock.Now().Add(tt.want))
+			}
+		})
+	}
+}
+
+func TestDirectMessageDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		dmsClosed    bool
+		wantChannel  string
+		wantFallback bool
+	}{
+		{name: "delivered by DM", wantChannel: discordtest.DMChannelID("alice")},
+		{name: "DMs closed falls back to the channel", dmsClosed: true, wantChannel: "channel-1", wantFallback: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := newTestBot(t)
+			if tt.dmsClosed {
+				bot.session.FailChannel(discordtest.DMChannelID("alice"), http.StatusForbidden)
+			}
+
+			bot.reply(t, alice.Command("memo",
+				discordtest.String("content", "call the bank"),
+				discordtest.String("when", "in 1 hour"),
+				discordtest.String("deliver", service.DeliveryDM),
+			))
+			bot.clock.Advance(time.Hour)
+			bot.deliverDue(t)
+
+			messages := bot.session.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(messages))
+			}
+			if messages[0].ChannelID != tt.wantChannel {
+				t.Errorf("delivered to %s, want %s", messages[0].ChannelID, tt.wantChannel)
+			}
+			if got := strings.Contains(messages[0].Send.Content, "I couldn't DM you"); got != tt.wantFallback {
+				t.Errorf("fallback notice = %v, want %v in %q", got, tt.wantFallback, messages[0].Send.Content)
+			}
+			if tt.wantFallback && !strings.HasPrefix(messages[0].Send.Content, "<@alice>") {
+				t.Errorf("fallback = %q, want it to ping the owner", messages[0].Send.Content)
+			}
+			if !tt.wantFallback && messages[0].Send.Content != "" {
+				t.Errorf("direct message content = %q, want only the embed", messages[0].Send.Content)
+			}
+		})
+	}
+}
+
+func TestDeliverBothWithChannelFailing(t *testing.T) {
+	bot := newTestBot(t)
+	bot.reply(t, alice.Command("memo",
+		discordtest.String("content", "call the bank"),
+		discordtest.String("when", "in 1 hour"),
+		discordtest.String("deliver", service.DeliveryBoth),
+	))
+	bot.session.FailChannel("channel-1", http.StatusInternalServerError)
+	bot.clock.Advance(time.Hour)
+
+	// The DM went out, so the memo isn't retried and DMed again
+	bot.deliverDue(t)
+	bot.clock.Advance(time.Hour)
+	bot.deliverDue(t)
+
+	messages := bot.session.Messages()
+	if len(messages) != 1 || messages[0].ChannelID != discordtest.DMChannelID("alice") {
+		t.Fatalf("sent %d messages, want only the DM", len(messages))
+	}
+	if memo := bot.store.Memos()[0]; !memo.Sent.Bool || memo.Attempts != 0 {
+		t.Fatalf("memo sent = %v after %d failed attempts, want it delivered", memo.Sent.Bool, memo.Attempts)
+	}
+}
+
+func TestSendReminderPermanentFailure(t *testing.T) {
+	bot := newTestBot(t)
+	bot.reply(t, alice.Command("memo",
+		discordtest.String("content", "stand-up"),
+		discordtest.String("when", "in 10 minutes"),
+	))
+	bot.session.FailChannel("channel-1", http.StatusNotFound)
+	bot.clock.Advance(10 * time.Minute)
+
+	memos, err := bot.service.ClaimDueReminders(context.Background(), "worker-1", bot.clock.Now(), time.Minute, 10)
+	if err != nil || len(memos) != 1 {
+		t.Fatalf("ClaimDueReminders() = %d memos, %v", len(memos), err)
+	}
+	err = bot.client.SendReminder(context.Background(), memos[0])
+	if err == nil || !discord.IsPermanentError(err) {
+		t.Fatalf("SendReminder() error = %v, want a permanent error", err)
+	}
+}
+
+func TestDeleteCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		invoker   discordtest.Invoker
+		memoID    int64
+		want      string
+		wantMemos int
+		wantLog   bool
+	}{
+		{name: "owner deletes", invoker: alice, memoID: 1, want: "✅ Memo deleted", wantMemos: 0},
+		{name: "other user is refused", invoker: bob, memoID: 1, want: "❌ memo #1 belongs to <@alice>", wantMemos: 1},
+		{name: "unknown memo", invoker: alice, memoID: 7, want: "❌ reminder #7 not found", wantMemos: 1},
+		{name: "moderator deletes", invoker: mod, memoID: 1, want: "✅ Memo #1 of <@alice> deleted", wantMemos: 0, wantLog: true},
+		{
+			name:      "moderator in another channel is sent there",
+			invoker:   discordtest.Invoker{UserID: "mod", GuildID: "guild-1", ChannelID: "channel-2", Permissions: discordgo.PermissionManageMessages},
+			memoID:    1,
+			want:      "❌ memo #1 is posted in <#channel-1>",
+			wantMemos: 1,
+		},
+		{
+			name:      "moderator of another server is refused",
+			invoker:   discordtest.Invoker{UserID: "mod", GuildID: "guild-2", ChannelID: "channel-1", Permissions: discordgo.PermissionManageMessages},
+			memoID:    1,
+			want:      "❌ memo #1 belongs to <@alice>",
+			wantMemos: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := newTestBot(t)
+			bot.reply(t, alice.Command("memo",
+				discordtest.String("content", "stand-up"),
+				discordtest.String("when", "in 10 minutes"),
+			))
+
+			data := bot.reply(t, tt.invoker.Command("delete", discordtest.Int("id", tt.memoID)))
+			if !strings.HasPrefix(data.Content, tt.want) {
+				t.Fatalf("/delete response = %q, want prefix %q", data.Content, tt.want)
+			}
+			if n := len(bot.store.Memos()); n != tt.wantMemos {
+				t.Fatalf("%d memos left, want %d", n, tt.wantMemos)
+			}
+
+			log := bot.store.ModerationLog()
+			if !tt.wantLog {
+				if len(log) != 0 {
+					t.Fatalf("moderation log = %+v, want it empty", log)
+				}
+				return
+			}
+			if len(log) != 1 || log[0].Action != service.ModerationDelete || log[0].ModeratorID != "mod" || log[0].OwnerID != "alice" {
+				t.Fatalf("moderation log = %+v, want mod's deletion of alice's memo", log)
+			}
+		})
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	bot := newTestBot(t)
+	for i := 1; i <= 7; i++ {
+		bot.reply(t, alice.Command("memo",
+			discordtest.String("content", "task"),
+			discordtest.String("when", fmt.Sprintf("in %d hours", i)),
+		))
+	}
+
+	first := bot.reply(t, alice.Command("list"))
+	if len(first.Embeds) != 1 || len(first.Embeds[0].Fields) != 5 {
+		t.Fatalf("first page = %+v, want one embed with 5 fields", first.Embeds)
+	}
+	if footer := first.Embeds[0].Footer.Text; !strings.HasPrefix(footer, "Page 1/2") {
+		t.Fatalf("footer = %q", footer)
+	}
+
+	page := &discordgo.Message{Embeds: first.Embeds, Components: first.Components}
+	second := bot.reply(t, alice.Click(page, "list:mine:1"))
+	if len(second.Embeds[0].Fields) != 2 {
+		t.Fatalf("second page has %d fields, want 2", len(second.Embeds[0].Fields))
+	}
+	if !strings.HasPrefix(second.Embeds[0].Fields[0].Name, "#6 ") {
+		t.Fatalf("second page starts with %q, want memo #6", second.Embeds[0].Fields[0].Name)
+	}
+}
+
+func TestRegisterCommands(t *testing.T) {
+	tests := []struct {
+		name       string
+		scope      discord.CommandScope
+		wantGuilds []string
+	}{
+		{name: "global", wantGuilds: []string{""}},
+		{name: "dev guilds", scope: discord.CommandScope{GuildIDs: []string{"guild-1", "guild-2"}}, wantGuilds: []string{"guild-1", "guild-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := newTestBot(t)
+			tt.scope.UnregisterOnClose = true
+			bot.client.SetCommandScope(tt.scope)
+
+			if err := bot.client.RegisterCommands("app-1"); err != nil {
+				t.Fatalf("RegisterCommands() error = %v", err)
+			}
+			for _, guildID := range tt.wantGuilds {
+				if n := len(bot.session.Commands(guildID)); n == 0 {
+					t.Errorf("no commands registered in scope %q", guildID)
+				}
+			}
+			if len(tt.wantGuilds) > 0 && tt.wantGuilds[0] != "" && len(bot.session.Commands("")) != 0 {
+				t.Errorf("commands registered globally, want guild scope only")
+			}
+
+			// Registering again replaces the set instead of adding to it
+			first := len(bot.session.Commands(tt.wantGuilds[0]))
+			if err := bot.client.RegisterCommands("app-1"); err != nil {
+				t.Fatal(err)
+			}
+			if n := len(bot.session.Commands(tt.wantGuilds[0])); n != first {
+				t.Errorf("%d commands after re-registering, want %d", n, first)
+			}
+
+			if err := bot.client.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			for _, guildID := range tt.wantGuilds {
+				if n := len(bot.session.Commands(guildID)); n != 0 {
+					t.Errorf("%d commands left in scope %q after Close, want 0", n, guildID)
+				}
+			}
+		})
+	}
+}
+
+func TestMentionTargets(t *testing.T) {
+	oncall := &discordgo.Role{ID: "role-oncall", Name: "oncall", Mentionable: true}
+	admins := &discordgo.Role{ID: "role-admins", Name: "admins"}
+	member := discordtest.Invoker{UserID: "alice", GuildID: "guild-1", ChannelID: "channel-1", Roles: []*discordgo.Role{oncall, admins}}
+	moderator := member
+	moderator.Permissions = discordgo.PermissionMentionEveryone
+
+	tests := []struct {
+		name      string
+		invoker   discordtest.Invoker
+		options   []*discordgo.ApplicationCommandInteractionDataOption
+		wantErr   string
+		wantPing  string
+		wantUsers []string
+		wantRoles []string
+	}{
+		{
+			name:      "user",
+			invoker:   member,
+			options:   []*discordgo.ApplicationCommandInteractionDataOption{discordtest.User("target_user", "bob")},
+			wantPing:  "<@alice> <@bob>",
+			wantUsers: []string{"alice", "bob"},
+		},
+		{
+			name:      "mentionable role",
+			invoker:   member,
+			options:   []*discordgo.ApplicationCommandInteractionDataOption{discordtest.Role("target_role", "role-oncall")},
+			wantPing:  "<@alice> <@&role-oncall>",
+			wantUsers: []string{"alice"},
+			wantRoles: []string{"role-oncall"},
+		},
+		{
+			name:    "role the member can't mention",
+			invoker: member,
+			options: []*discordgo.ApplicationCommandInteractionDataOption{discordtest.Role("target_role", "role-admins")},
+			wantErr: "you can't ping <@&role-admins>",
+		},
+		{
+			name:      "role with Mention @everyone permission",
+			invoker:   moderator,
+			options:   []*discordgo.ApplicationCommandInteractionDataOption{discordtest.Role("target_role", "role-admins"), discordtest.User("target_user", "bob")},
+			wantPing:  "<@alice> <@bob> <@&role-admins>",
+			wantUsers: []string{"alice", "bob"},
+			wantRoles: []string{"role-admins"},
+		},
+		{
+			name:    "@everyone",
+			invoker: moderator,
+			options: []*discordgo.ApplicationCommandInteractionDataOption{discordtest.Role("target_role", "guild-1")},
+			wantErr: "can't ping @everyone",
+		},
+		{
+			name:    "direct message delivery",
+			invoker: member,
+			options: []*discordgo.ApplicationCommandInteractionDataOption{discordtest.User("target_user", "bob"), discordtest.String("deliver", service.DeliveryDM)},
+			wantErr: "must be delivered in the channel",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := newTestBot(t)
+			options := append([]*discordgo.ApplicationCommandInteractionDataOption{
+				discordtest.String("content", "check the deploy @everyone"),
+				discordtest.String("when", "in 1 hour"),
+			}, tt.options...)
+
+			data := bot.reply(t, tt.invoker.Command("memo", options...))
+			if tt.wantErr != "" {
+				if !strings.Contains(data.Content, tt.wantErr) {
+					t.Fatalf("/memo response = %q, want error %q", data.Content, tt.wantErr)
+				}
+				return
+			}
+
+			bot.clock.Advance(time.Hour)
+			bot.deliverDue(t)
+			messages := bot.session.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(messages))
+			}
+			msg := messages[0].Send
+			if msg.Content != tt.wantPing {
+				t.Errorf("reminder = %q, want %q", msg.Content, tt.wantPing)
+			}
+			allowed := msg.AllowedMentions
+			if allowed == nil || len(allowed.Parse) != 0 {
+				t.Fatalf("AllowedMentions = %+v, want only explicit targets", allowed)
+			}
+			if fmt.Sprint(allowed.Users) != fmt.Sprint(tt.wantUsers) || fmt.Sprint(allowed.Roles) != fmt.Sprint(tt.wantRoles) {
+				t.Errorf("allowed users %v roles %v, want %v and %v", allowed.Users, allowed.Roles, tt.wantUsers, tt.wantRoles)
+			}
+		})
+	}
+}
+
+func TestReminderContentCantPing(t *testing.T) {
+	bot := newTestBot(t)
+	bot.reply(t, alice.Command("memo",
+		discordtest.String("content", "@everyone <@&role-admins> <@bob> deploy is done"),
+		discordtest.String("when", "in 10 minutes"),
+	))
+	bot.clock.Advance(10 * time.Minute)
+	bot.deliverDue(t)
+
+	msg := bot.session.Messages()[0].Send
+	if msg.Content != "<@alice>" {
+		t.Errorf("reminder content = %q, want only the owner's mention", msg.Content)
+	}
+	allowed := msg.AllowedMentions
+	if allowed == nil || len(allowed.Parse) != 0 || len(allowed.Roles) != 0 || fmt.Sprint(allowed.Users) != "[alice]" {
+		t.Fatalf("AllowedMentions = %+v, want only the owner", allowed)
+	}
+}
+
+func TestConfigCommand(t *testing.T) {
+	bot := newTestBot(t)
+	admin := alice
+	admin.Permissions = discordgo.PermissionManageServer
+
+	data := bot.reply(t, bob.Command("config", discordtest.Subcommand("set", discordtest.Bool("list_others", false))))
+	if !strings.Contains(data.Content, "❌ you need the Manage Server permission") {
+		t.Fatalf("/config by a member = %q, want a permission error", data.Content)
+	}
+
+	data = bot.reply(t, admin.Command("config", discordtest.Subcommand("set",
+		discordtest.String("timezone", "Asia/Tokyo"),
+		discordtest.Channel("delivery_channel", "reminders"),
+		discordtest.Bool("list_others", false),
+		discordtest.Int("max_pending", 1),
+	)))
+	for _, want := range []string{"✅ Settings saved", "Asia/Tokyo", "<#reminders>", "only shows members their own memos", "**1**"} {
+		if !strings.Contains(data.Content, want) {
+			t.Errorf("/config set = %q, want it to contain %q", data.Content, want)
+		}
+	}
+
+	// Memos use the server's timezone and channel
+	data = bot.reply(t, bob.Command("memo",
+		discordtest.String("content", "stand-up"),
+		discordtest.String("when", "today at 7pm"),
+	))
+	if !strings.Contains(data.Content, "JST") || !strings.Contains(data.Content, "📍 Will be posted in <#reminders>") {
+		t.Fatalf("/memo response = %q, want Tokyo time and the delivery channel", data.Content)
+	}
+	if memo := bot.store.Memos()[0]; memo.DiscordChannelID != "reminders" || !memo.RemindAt.Equal(time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("memo in %s at %s, want reminders at 10:00 UTC", memo.DiscordChannelID, memo.RemindAt)
+	}
+
+	data = bot.reply(t, bob.Command("memo",
+		discordtest.String("content", "another one"),
+		discordtest.String("when", "in 1 hour"),
+	))
+	if !strings.Contains(data.Content, "❌ you already have 1 pending memos") {
+		t.Fatalf("/memo over the limit = %q, want a limit error", data.Content)
+	}
+
+	data = bot.reply(t, bob.Command("list", discordtest.String("show", "channel")))
+	if !strings.Contains(data.Content, "❌ this server doesn't allow listing other members' memos") {
+		t.Fatalf("/list show:channel = %q, want it refused", data.Content)
+	}
+
+	data = bot.reply(t, admin.Command("config", discordtest.Subcommand("reset", discordtest.String("setting", "all"))))
+	for _, want := range []string{"UTC (bot default)", "the channel each memo was created in", "can show other members' memos", "no limit"} {
+		if !strings.Contains(data.Content, want) {
+			t.Errorf("/config reset = %q, want it to contain %q", data.Content, want)
+		}
+	}
+}
+
+func TestTimezoneCommandInDM(t *testing.T) {
+	bot := newTestBot(t)
+
+	data := bot.reply(t, aliceDM.Command("timezone", discordtest.Subcommand("set", discordtest.String("name", "Asia/Tokyo"))))
+	if !strings.HasPrefix(data.Content, "✅ Your timezone is now **Asia/Tokyo**") {
+		t.Fatalf("/timezone set in a DM = %q", data.Content)
+	}
+	data = bot.reply(t, aliceDM.Command("timezone", discordtest.Subcommand("show")))
+	if !strings.HasPrefix(data.Content, "🌍 Your timezone is **Asia/Tokyo**") {
+		t.Fatalf("/timezone show in a DM = %q", data.Content)
+	}
+
+	// The DM setting is the same user's setting in servers
+	data = bot.reply(t, alice.Command("timezone", discordtest.Subcommand("show")))
+	if !strings.HasPrefix(data.Content, "🌍 Your timezone is **Asia/Tokyo**") {
+		t.Fatalf("/timezone show in a server = %q", data.Content)
+	}
+}
+
+func TestEditCommandInDM(t *testing.T) {
+	bot := newTestBot(t)
+	bot.reply(t, aliceDM.Command("memo",
+		discordtest.String("content", "stand-up"),
+		discordtest.String("when", "in 10 minutes"),
+	))
+
+	data := bot.reply(t, aliceDM.Command("edit",
+		discordtest.Int("id", 1),
+		discordtest.String("content", "retro"),
+		discordtest.String("when", "in 1 hour"),
+	))
+	if !strings.HasPrefix(data.Content, "✅ Memo #1 updated") || !strings.Contains(data.Content, "retro") {
+		t.Fatalf("/edit in a DM = %q", data.Content)
+	}
+	memo := bot.store.Memos()[0]
+	if memo.Content != "retro" || !memo.RemindAt.Equal(testStart.Add(time.Hour)) {
+		t.Fatalf("memo = %q at %s, want the edit applied", memo.Content, memo.RemindAt)
+	}
+
+	data = bot.reply(t, bob.Command("edit", discordtest.Int("id", 1), discordtest.String("content", "mine now")))
+	if !strings.HasPrefix(data.Content, "❌") {
+		t.Fatalf("/edit by another user = %q, want it refused", data.Content)
+	}
+}
+
+func TestReassignCommand(t *testing.T) {
+	bot := newTestBot(t)
+	bot.reply(t, alice.Command("memo",
+		discordtest.String("content", "stand-up"),
+		discordtest.String("when", "in 10 minutes"),
+	))
+
+	data := bot.reply(t, bob.Command("reassign", discordtest.Int("id", 1), discordtest.User("user", "bob")))
+	if !strings.HasPrefix(data.Content, "❌ memo #1 belongs to <@alice>") {
+		t.Fatalf("/reassign by a member = %q, want it refused", data.Content)
+	}
+
+	data = bot.reply(t, mod.Command("reassign", discordtest.Int("id", 1), discordtest.User("user", "bob")))
+	if data.Content != "✅ Memo #1 reassigned from <@alice> to <@bob>" {
+		t.Fatalf("/reassign by a moderator = %q", data.Content)
+	}
+	if owner := bot.store.Memos()[0].DiscordUserID; owner != "bob" {
+		t.Fatalf("memo owner = %s, want bob", owner)
+	}
+	log := bot.store.ModerationLog()
+	if len(log) != 1 || log[0].Action != service.ModerationReassign || log[0].NewOwnerID.String != "bob" || log[0].ModeratorID != "mod" {
+		t.Fatalf("moderation log = %+v, want mod's reassignment to bob", log)
+	}
+
+	// The new owner is pinged and can use the buttons
+	bot.clock.Advance(10 * time.Minute)
+	bot.deliverDue(t)
+	if msg := bot.session.Messages()[0].Send; msg.Content != "<@bob>" {
+		t.Fatalf("reminder = %q, want a ping of the new owner", msg.Content)
+	}
+}
+
+func TestPurgeCommand(t *testing.T) {
+	bot := newTestBot(t)
+	elsewhere := discordtest.Invoker{UserID: "alice", GuildID: "guild-2", ChannelID: "channel-9"}
+	for _, inv := range []discordtest.Invoker{alice, alice, bob, elsewhere} {
+		bot.reply(t, inv.Command("memo",
+			discordtest.String("content", "stand-up"),
+			discordtest.String("when", "in 10 minutes"),
+		))
+	}
+
+	data := bot.reply(t, mod.Command("purge", discordtest.User("user", "alice")))
+	if !strings.HasPrefix(data.Content, "❌ you need the Manage Server permission") {
+		t.Fatalf("/purge without Manage Server = %q, want it refused", data.Content)
+	}
+
+	admin := alice
+	admin.UserID = "admin"
+	admin.Permissions = discordgo.PermissionManageServer
+	data = bot.reply(t, admin.Command("purge", discordtest.User("user", "alice")))
+	if data.Content != "🧹 Deleted 2 pending memo(s) of <@alice>" {
+		t.Fatalf("/purge = %q", data.Content)
+	}
+
+	// bob's memo and alice's memo in the other server are kept
+	var left []string
+	for _, m := range bot.store.Memos() {
+		left = append(left, m.DiscordUserID+"@"+m.GuildID.String)
+	}
+	if fmt.Sprint(left) != "[bob@guild-1 alice@guild-2]" {
+		t.Fatalf("memos left = %v", left)
+	}
+	if n := len(bot.store.ModerationLog()); n != 2 {
+		t.Fatalf("moderation log has %d entries, want 2", n)
+	}
+
+	data = bot.reply(t, admin.Command("purge", discordtest.User("user", "alice")))
+	if data.Content != "<@alice> has no pending memos in this server" {
+		t.Fatalf("second /purge = %q", data.Content)
+	}
+}
+
+func TestPurgeCommandLegacyMemos(t *testing.T) {
+	bot := newTestBot(t)
+	bot.session.Channels["channel-1"] = &discordgo.Channel{ID: "channel-1", GuildID: "guild-1"}
+	bot.session.Channels["channel-9"] = &discordgo.Channel{ID: "channel-9", GuildID: "guild-2"}
+	bot.session.Channels[discordtest.DMChannelID("alice")] = &discordgo.Channel{ID: discordtest.DMChannelID("alice"), Type: discordgo.ChannelTypeDM}
+
+	bot.reply(t, alice.Command("memo",
+		discordtest.String("content", "stand-up"),
+		discordtest.String("when", "in 10 minutes"),
+	))
+	// Memos from before the server was recorded have no guild ID
+	for _, channelID := range []string{"channel-1", "channel-9", "deleted-channel", discordtest.DMChannelID("alice")} {
+		if _, err := bot.service.CreateMemo(context.Background(), "alice", channelID, "old memo", bot.clock.Now().Add(time.Hour), service.MemoOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	admin := alice
+	admin.UserID = "admin"
+	admin.Permissions = discordgo.PermissionManageServer
+	data := bot.reply(t, admin.Command("purge", discordtest.User("user", "alice")))
+	want := "🧹 Deleted 2 pending memo(s) of <@alice>\n⚠️ 1 older memo(s) of <@alice> are in channels I can't look up anymore"
+	if !strings.HasPrefix(data.Content, want) {
+		t.Fatalf("/purge = %q, want prefix %q", data.Content, want)
+	}
+
+	var left []string
+	for _, m := range bot.store.Memos() {
+		left = append(left, m.DiscordChannelID)
+	}
+	if fmt.Sprint(left) != "[channel-9 deleted-channel dm-alice]" {
+		t.Fatalf("memos left in %v, want the other server's, the unknown channel's and the DM's", left)
+	}
+	for _, entry := range bot.store.ModerationLog() {
+		if entry.GuildID.String != "guild-1" {
+			t.Fatalf("moderation log entry %+v, want it recorded in guild-1", entry)
+		}
+	}
+}
+
+func TestMemoRateLimit(t *testing.T) {
+	bot := newTestBot(t)
+	bot.client.SetMemoRateLimit(ratelimit.New(6, 2, bot.clock))
+	memo := func(inv discordtest.Invoker) string {
+		return bot.reply(t, inv.Command("memo",
+			discordtest.String("content", "stand-up"),
+			discordtest.String("when", "in 10 minutes"),
+		)).Content
+	}
+
+	memo(alice)
+	// Opening the modal counts too
+	if data := bot.reply(t, alice.Command("memo")); data.CustomID == "" {
+		t.Fatalf("/memo without options = %q, want the modal", data.Content)
+	}
+	if got := memo(alice); got != "❌ you're creating memos too quickly. Try again in 10s" {
+		t.Fatalf("/memo past the burst = %q, want it refused", got)
+	}
+	if n := len(bot.store.Memos()); n != 1 {
+		t.Fatalf("%d memos stored, want 1", n)
+	}
+
+	if got := memo(bob); !strings.HasPrefix(got, "✅") {
+		t.Fatalf("/memo by bob = %q, want it allowed", got)
+	}
+	bot.clock.Advance(10 * time.Second)
+	if got := memo(alice); !strings.HasPrefix(got, "✅") {
+		t.Fatalf("/memo after the refill = %q, want it allowed", got)
+	}
+}
+
+// modalInput returns the prefilled value of a modal's text input
+func modalInput(t *testing.T, data *discordgo.InteractionResponseData, customID string) string {
+	t.Helper()
+	for _, row := range data.Components {
+		for _, c := range row.(discordgo.ActionsRow).Components {
+			if input, ok := c.(discordgo.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	t.Fatalf("modal %q has no input %q", data.CustomID, customID)
+	return ""
+}
+
+func TestMemoModalTruncatesContent(t *testing.T) {
+	bot := newTestBot(t)
+
+	data := bot.reply(t, alice.Command("memo", discordtest.String("content", strings.Repeat("日本", 1000))))
+	content := modalInput(t, data, "content")
+	if !utf8.ValidString(content) {
+		t.Fatalf("content is not valid UTF-8: %q", content)
+	}
+	if n := utf8.RuneCountInString(content); n != 1800 {
+		t.Fatalf("content has %d characters, want 1800", n)
+	}
+}
+
+func TestMemoReplyShortensContent(t *testing.T) {
+	bot := newTestBot(t)
+
+	data := bot.reply(t, alice.Command("memo",
+		discordtest.String("content", strings.Repeat("é", 60)),
+		discordtest.String("when", "in 10 minutes"),
+	))
+	want := "✅ <@alice> created a memo: " + strings.Repeat("é", 49) + "…\n"
+	if !utf8.ValidString(data.Content) || !strings.HasPrefix(data.Content, want) {
+		t.Fatalf("/memo response = %q, want prefix %q", data.Content, want)
+	}
+}
+
+func TestRemindMessageTruncatesNote(t *testing.T) {
+	bot := newTestBot(t)
+	target := &discordgo.Message{ID: "message-1", ChannelID: "channel-1", Content: strings.Repeat("🎉", 1200)}
+
+	data := bot.reply(t, alice.MessageCommand("Remind me about this", target))
+	note := modalInput(t, data, "note")
+	if !utf8.ValidString(note) {
+		t.Fatalf("note is not valid UTF-8: %q", note)
+	}
+	if n := utf8.RuneCountInString(note); n != 1000 || !strings.HasSuffix(note, "…") {
+		t.Fatalf("note has %d characters ending in %q, want 1000 ending in an ellipsis", n, note[len(note)-3:])
+	}
+}
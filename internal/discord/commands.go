@@ -0,0 +1,64 @@
+package discord
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// CommandScope controls where slash commands are registered
+type CommandScope struct {
+	// GuildIDs registers the commands in these guilds only, where changes show
+	// up instantly. Empty registers them globally, which can take up to an hour.
+	GuildIDs []string
+	// UnregisterOnClose removes the commands when the client is closed
+	UnregisterOnClose bool
+}
+
+// SetCommandScope sets where Connect registers slash commands
+func (c *Client) SetCommandScope(scope CommandScope) {
+	c.scope = scope
+}
+
+// scopeGuildIDs lists the guilds to register in, with "" for global registration
+func (c *Client) scopeGuildIDs() []string {
+	if len(c.scope.GuildIDs) == 0 {
+		return []string{""}
+	}
+	return c.scope.GuildIDs
+}
+
+// RegisterCommands replaces the application's commands in every configured
+// scope with the current set, so commands removed from the bot disappear too
+func (c *Client) RegisterCommands(appID string) error {
+	c.appID = appID
+	for _, guildID := range c.scopeGuildIDs() {
+		registered, err := c.session.ApplicationCommandBulkOverwrite(appID, guildID, commands)
+		if err != nil {
+			return fmt.Errorf("failed to register slash commands%s: %w", describeScope(guildID), err)
+		}
+		slog.Info("Registered slash commands", "count", len(registered), "guild_id", guildID)
+	}
+	return nil
+}
+
+// UnregisterCommands removes every command of the application from the
+// configured scopes
+func (c *Client) UnregisterCommands() error {
+	if c.appID == "" {
+		return nil
+	}
+	for _, guildID := range c.scopeGuildIDs() {
+		if _, err := c.session.ApplicationCommandBulkOverwrite(c.appID, guildID, nil); err != nil {
+			return fmt.Errorf("failed to unregister slash commands%s: %w", describeScope(guildID), err)
+		}
+		slog.Info("Unregistered slash commands", "guild_id", guildID)
+	}
+	return nil
+}
+
+func describeScope(guildID string) string {
+	if guildID == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in guild %s", guildID)
+}
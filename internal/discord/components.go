@@ -0,0 +1,168 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo-bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom IDs of message components have the form "<action>:<memo id>[:<arg>]"
+const (
+	actionSnooze = "snooze"
+	actionDone   = "done"
+)
+
+var snoozeOptions = []struct {
+	key   string
+	label string
+}{
+	{"10m", "Snooze 10m"},
+	{"1h", "Snooze 1h"},
+	{"tomorrow", "Tomorrow"},
+}
+
+// reminderComponents builds the Snooze / Done buttons attached to a delivered reminder
+func reminderComponents(memoID int32) []discordgo.MessageComponent {
+	var buttons []discordgo.MessageComponent
+	for _, opt := range snoozeOptions {
+		buttons = append(buttons, discordgo.Button{
+			Label:    opt.label,
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("%s:%d:%s", actionSnooze, memoID, opt.key),
+			Emoji:    &discordgo.ComponentEmoji{Name: "💤"},
+		})
+	}
+	buttons = append(buttons, discordgo.Button{
+		Label:    "Done",
+		Style:    discordgo.SuccessButton,
+		CustomID: fmt.Sprintf("%s:%d", actionDone, memoID),
+		Emoji:    &discordgo.ComponentEmoji{Name: "✅"},
+	})
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: buttons},
+	}
+}
+
+// handleComponent routes button clicks on delivered reminders and /list pages
+func (c *Client) handleComponent(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if parts[0] == actionList {
+		c.handleListComponent(ctx, s, i)
+		return
+	}
+	if len(parts) < 2 {
+		return
+	}
+
+	memoID, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Invalid memo ID in component", "custom_id", i.MessageComponentData().CustomID, logging.KeyError, err)
+		return
+	}
+
+	var status string
+	switch parts[0] {
+	case actionSnooze:
+		if len(parts) != 3 {
+			return
+		}
+		status, err = c.handleSnooze(ctx, i, int32(memoID), parts[2])
+	case actionDone:
+		status, err = c.handleDone(ctx, i, int32(memoID))
+	default:
+		return
+	}
+
+	if err != nil {
+		c.respondEphemeral(ctx, s, i, fmt.Sprintf("❌ %s", err))
+		return
+	}
+
+	// Replace the buttons with the outcome so the reminder can't be actioned
+	// twice. Embeds must be sent back, an update without them removes them.
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    i.Message.Content + "\n" + status,
+			Components: []discordgo.MessageComponent{},
+			Embeds:     i.Message.Embeds,
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error responding to component interaction", logging.KeyError, err)
+	}
+}
+
+func (c *Client) handleSnooze(ctx context.Context, i *discordgo.InteractionCreate, memoID int32, key string) (string, error) {
+	userID := interactionUserID(i)
+	loc := c.userLocation(ctx, userID, i.GuildID)
+
+	now := c.clock.Now().In(loc)
+	var until time.Time
+	switch key {
+	case "10m":
+		until = now.Add(10 * time.Minute)
+	case "1h":
+		until = now.Add(time.Hour)
+	case "tomorrow":
+		until = now.AddDate(0, 0, 1)
+	default:
+		return "", fmt.Errorf("unknown snooze option %q", key)
+	}
+
+	if err := c.service.SnoozeMemo(ctx, memoID, userID, until); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("💤 <@%s> snoozed until %s", userID, until.Format("Monday, January 2, 2006 at 15:04 MST")), nil
+}
+
+func (c *Client) handleDone(ctx context.Context, i *discordgo.InteractionCreate, memoID int32) (string, error) {
+	userID := interactionUserID(i)
+	if err := c.service.AcknowledgeMemo(ctx, memoID, userID); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✅ Marked as done by <@%s>", userID), nil
+}
+
+// interactionUserID returns the invoking user for both guild and DM interactions
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// interactionUsername returns the invoking user's name like interactionUserID
+func interactionUsername(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.Username
+	}
+	if i.User != nil {
+		return i.User.Username
+	}
+	return ""
+}
+
+func (c *Client) respondEphemeral(ctx context.Context, s Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error responding to interaction", logging.KeyError, err)
+	}
+}
@@ -0,0 +1,181 @@
+package discordtest
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var interactionIDs atomic.Int64
+
+// Invoker is the guild member and channel an interaction comes from
+type Invoker struct {
+	UserID    string
+	GuildID   string
+	ChannelID string
+	// Permissions are the member's permissions in the channel
+	Permissions int64
+	// Roles are the guild's roles, used to resolve role options
+	Roles []*discordgo.Role
+	// DM sends interactions from a direct message with the bot. Like Discord,
+	// they carry User instead of Member and no guild.
+	DM bool
+}
+
+func (inv Invoker) interaction(typ discordgo.InteractionType, data discordgo.InteractionData) *discordgo.InteractionCreate {
+	i := &discordgo.Interaction{
+		ID:        strconv.FormatInt(interactionIDs.Add(1), 10),
+		Type:      typ,
+		ChannelID: inv.ChannelID,
+		Data:      data,
+	}
+	user := &discordgo.User{ID: inv.UserID, Username: inv.UserID}
+	if inv.DM {
+		i.User = user
+	} else {
+		i.GuildID = inv.GuildID
+		i.Member = &discordgo.Member{User: user, Permissions: inv.Permissions}
+	}
+	return &discordgo.InteractionCreate{Interaction: i}
+}
+
+// Command builds a slash command interaction. User and role options are
+// resolved like Discord does, roles from inv.Roles.
+func (inv Invoker) Command(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return inv.interaction(discordgo.InteractionApplicationCommand, discordgo.ApplicationCommandInteractionData{
+		Name:        name,
+		CommandType: discordgo.ChatApplicationCommand,
+		Options:     options,
+		Resolved:    inv.resolve(options),
+	})
+}
+
+func (inv Invoker) resolve(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataResolved {
+	resolved := &discordgo.ApplicationCommandInteractionDataResolved{
+		Users: make(map[string]*discordgo.User),
+		Roles: make(map[string]*discordgo.Role),
+	}
+	for _, opt := range options {
+		id, _ := opt.Value.(string)
+		switch opt.Type {
+		case discordgo.ApplicationCommandOptionUser:
+			resolved.Users[id] = &discordgo.User{ID: id, Username: id}
+		case discordgo.ApplicationCommandOptionRole:
+			resolved.Roles[id] = &discordgo.Role{ID: id, Name: id}
+			for _, role := range inv.Roles {
+				if role.ID == id {
+					resolved.Roles[id] = role
+				}
+			}
+		}
+	}
+	return resolved
+}
+
+// MessageCommand builds a message context menu command used on target
+func (inv Invoker) MessageCommand(name string, target *discordgo.Message) *discordgo.InteractionCreate {
+	return inv.interaction(discordgo.InteractionApplicationCommand, discordgo.ApplicationCommandInteractionData{
+		Name:        name,
+		CommandType: discordgo.MessageApplicationCommand,
+		TargetID:    target.ID,
+		Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+			Messages: map[string]*discordgo.Message{target.ID: target},
+		},
+	})
+}
+
+// Click builds a button click on a message
+func (inv Invoker) Click(message *discordgo.Message, customID string) *discordgo.InteractionCreate {
+	i := inv.interaction(discordgo.InteractionMessageComponent, discordgo.MessageComponentInteractionData{
+		CustomID:      customID,
+		ComponentType: discordgo.ButtonComponent,
+	})
+	i.Message = message
+	return i
+}
+
+// Select builds a select menu choice on a message
+func (inv Invoker) Select(message *discordgo.Message, customID string, values ...string) *discordgo.InteractionCreate {
+	i := inv.interaction(discordgo.InteractionMessageComponent, discordgo.MessageComponentInteractionData{
+		CustomID:      customID,
+		ComponentType: discordgo.SelectMenuComponent,
+		Values:        values,
+	})
+	i.Message = message
+	return i
+}
+
+// String builds a string command option
+func String(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionString,
+		Value: value,
+	}
+}
+
+// Int builds an integer command option. Discord sends numbers as JSON, so the
+// value is stored as a float64 like a decoded payload.
+func Int(name string, value int64) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionInteger,
+		Value: float64(value),
+	}
+}
+
+// Bool builds a boolean command option
+func Bool(name string, value bool) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionBoolean,
+		Value: value,
+	}
+}
+
+// Channel builds a channel command option
+func Channel(name, channelID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionChannel,
+		Value: channelID,
+	}
+}
+
+// User builds a user command option
+func User(name, userID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionUser,
+		Value: userID,
+	}
+}
+
+// Role builds a role command option
+func Role(name, roleID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionRole,
+		Value: roleID,
+	}
+}
+
+// Subcommand builds a subcommand option holding options
+func Subcommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:    name,
+		Type:    discordgo.ApplicationCommandOptionSubCommand,
+		Options: options,
+	}
+}
+
+// Message turns a recorded message into the *discordgo.Message a button click refers to
+func (m Message) Message() *discordgo.Message {
+	return &discordgo.Message{
+		ChannelID:  m.ChannelID,
+		Content:    m.Send.Content,
+		Embeds:     m.Send.Embeds,
+		Components: m.Send.Components,
+	}
+}
@@ -0,0 +1,197 @@
+// Package discordtest provides a recording fake of discord.Session and helpers
+// to build interactions, so the bot's handlers can be exercised without a
+// network connection.
+package discordtest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Response is an interaction response recorded by the fake session
+type Response struct {
+	Interaction *discordgo.Interaction
+	Response    *discordgo.InteractionResponse
+}
+
+// Message is a channel message recorded by the fake session
+type Message struct {
+	ChannelID string
+	Send      *discordgo.MessageSend
+}
+
+// Session records every call the client makes and answers lookups from its
+// Users, Channels and Guilds maps. The zero value is not usable, use NewSession.
+type Session struct {
+	mu        sync.Mutex
+	responses []Response
+	messages  []Message
+	commands  map[string][]*discordgo.ApplicationCommand
+	failing   map[string]int
+	nextID    int
+
+	Users    map[string]*discordgo.User
+	Channels map[string]*discordgo.Channel
+	Guilds   map[string]*discordgo.Guild
+}
+
+// NewSession returns an empty fake session
+func NewSession() *Session {
+	return &Session{
+		failing:  make(map[string]int),
+		commands: make(map[string][]*discordgo.ApplicationCommand),
+		Users:    make(map[string]*discordgo.User),
+		Channels: make(map[string]*discordgo.Channel),
+		Guilds:   make(map[string]*discordgo.Guild),
+	}
+}
+
+// DMChannelID is the ID of the fake direct message channel with userID
+func DMChannelID(userID string) string {
+	return "dm-" + userID
+}
+
+// FailChannel makes messages sent to channelID fail with the given HTTP status,
+// e.g. http.StatusForbidden for a user with DMs closed. A status of 0 clears it.
+func (s *Session) FailChannel(channelID string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status == 0 {
+		delete(s.failing, channelID)
+		return
+	}
+	s.failing[channelID] = status
+}
+
+// Responses returns the interaction responses recorded so far
+func (s *Session) Responses() []Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Response(nil), s.responses...)
+}
+
+// LastResponse returns the most recent interaction response, or nil
+func (s *Session) LastResponse() *discordgo.InteractionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.responses) == 0 {
+		return nil
+	}
+	return s.responses[len(s.responses)-1].Response
+}
+
+// Messages returns the channel messages recorded so far
+func (s *Session) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Commands returns the application commands registered in a guild, or
+// globally for an empty guildID
+func (s *Session) Commands(guildID string) []*discordgo.ApplicationCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*discordgo.ApplicationCommand(nil), s.commands[guildID]...)
+}
+
+// Reset forgets every recorded call
+func (s *Session) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = nil
+	s.messages = nil
+	s.commands = make(map[string][]*discordgo.ApplicationCommand)
+}
+
+func (s *Session) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = append(s.responses, Response{Interaction: interaction, Response: resp})
+	return nil
+}
+
+func (s *Session) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Content: content}, options...)
+}
+
+func (s *Session) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.failing[channelID]; ok {
+		return nil, restError(status)
+	}
+
+	// Copy the message, the client may reuse it for a fallback delivery
+	sent := *data
+	s.messages = append(s.messages, Message{ChannelID: channelID, Send: &sent})
+	s.nextID++
+	return &discordgo.Message{
+		ID:         strconv.Itoa(s.nextID),
+		ChannelID:  channelID,
+		Content:    sent.Content,
+		Embeds:     sent.Embeds,
+		Components: sent.Components,
+	}, nil
+}
+
+func (s *Session) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{
+		ID:         DMChannelID(recipientID),
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{ID: recipientID}},
+	}, nil
+}
+
+func (s *Session) User(userID string, options ...discordgo.RequestOption) (*discordgo.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.Users[userID]; ok {
+		return u, nil
+	}
+	return nil, restError(http.StatusNotFound)
+}
+
+func (s *Session) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.Channels[channelID]; ok {
+		return ch, nil
+	}
+	return nil, restError(http.StatusNotFound)
+}
+
+func (s *Session) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if g, ok := s.Guilds[guildID]; ok {
+		return g, nil
+	}
+	return nil, restError(http.StatusNotFound)
+}
+
+func (s *Session) ApplicationCommandBulkOverwrite(appID string, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	registered := make([]*discordgo.ApplicationCommand, 0, len(commands))
+	for _, cmd := range commands {
+		c := *cmd
+		c.ApplicationID = appID
+		c.GuildID = guildID
+		registered = append(registered, &c)
+	}
+	s.commands[guildID] = registered
+	return registered, nil
+}
+
+// restError builds the error discordgo returns for a failed REST call
+func restError(status int) error {
+	return &discordgo.RESTError{
+		Response: &http.Response{StatusCode: status, Status: fmt.Sprintf("%d %s", status, http.StatusText(status))},
+		Message:  &discordgo.APIErrorMessage{Message: http.StatusText(status)},
+	}
+}
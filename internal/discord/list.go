@@ -0,0 +1,244 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"memo-bot/internal/db"
+	"memo-bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Pagination buttons use the custom ID "list:<scope>:<page>" and the filter menu
+// "list", so every page can be rebuilt from the interaction alone
+const actionList = "list"
+
+const (
+	listScopeMine    = "mine"
+	listScopeChannel = "channel"
+	listScopeAll     = "all"
+)
+
+const (
+	listPageSize = 5
+	// maxFieldValueLength is Discord's limit for an embed field value
+	maxFieldValueLength = 1024
+)
+
+var listScopes = []struct {
+	value string
+	label string
+	title string
+}{
+	{listScopeMine, "Mine in this channel", "Your memos in this channel"},
+	{listScopeChannel, "Everyone in this channel", "All memos in this channel"},
+	{listScopeAll, "Mine in all channels", "Your memos in all channels"},
+}
+
+// handleListCommand replies with the first page of the requested /list scope
+func (c *Client) handleListCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
+	scope := listScopeMine
+	if opt, ok := optionMap(i.ApplicationCommandData().Options)["show"]; ok {
+		scope = opt.StringValue()
+	}
+
+	data, err := c.listPage(ctx, s, i, scope, 0)
+	if err != nil {
+		c.respondEphemeral(ctx, s, i, fmt.Sprintf("❌ %s", err))
+		return
+	}
+	data.Flags = discordgo.MessageFlagsEphemeral
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error responding to interaction", logging.KeyError, err)
+	}
+}
+
+// handleListComponent turns the page or switches the filter of a /list reply
+func (c *Client) handleListComponent(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
+	componentData := i.MessageComponentData()
+
+	var scope string
+	var page int
+	if componentData.ComponentType == discordgo.SelectMenuComponent {
+		if len(componentData.Values) == 0 {
+			return
+		}
+		scope = componentData.Values[0]
+	} else {
+		parts := strings.Split(componentData.CustomID, ":")
+		if len(parts) != 3 {
+			return
+		}
+		n, err := strconv.Atoi(parts[2])
+		if err != nil {
+			logging.FromContext(ctx).Warn("Invalid page in component", "custom_id", componentData.CustomID, logging.KeyError, err)
+			return
+		}
+		scope, page = parts[1], n
+	}
+
+	data, err := c.listPage(ctx, s, i, scope, page)
+	if err != nil {
+		c.respondEphemeral(ctx, s, i, fmt.Sprintf("❌ %s", err))
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: data,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error responding to component interaction", logging.KeyError, err)
+	}
+}
+
+// listPage builds one page of memos for scope. Pages past the end are clamped so
+// stale buttons still work after memos were deleted.
+func (c *Client) listPage(ctx context.Context, s Session, i *discordgo.InteractionCreate, scope string, page int) (*discordgo.InteractionResponseData, error) {
+	userID := interactionUserID(i)
+
+	showOthers, err := c.listShowsOthers(ctx, i.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	var memos []db.Memo
+	switch scope {
+	case listScopeMine:
+		memos, err = c.service.ListPendingMemos(ctx, userID, i.ChannelID)
+	case listScopeChannel:
+		if !showOthers {
+			return nil, fmt.Errorf("this server doesn't allow listing other members' memos")
+		}
+		memos, err = c.service.ListAllPendingMemosInChannel(ctx, i.ChannelID)
+	case listScopeAll:
+		memos, err = c.service.ListUserPendingMemos(ctx, userID)
+	default:
+		return nil, fmt.Errorf("unknown list filter %q", scope)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Get counts across all channels for the user
+	counts, err := c.service.GetReminderCounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	for _, count := range counts {
+		total += int(count.Count)
+	}
+
+	pages := (len(memos) + listPageSize - 1) / listPageSize
+	if pages == 0 {
+		pages = 1
+	}
+	page = max(0, min(page, pages-1))
+
+	loc := c.userLocation(ctx, userID, i.GuildID)
+	embed := &discordgo.MessageEmbed{
+		Title: listScopeTitle(scope),
+		Color: 0x5865F2,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%d · %d memo(s) · %d personal memo(s) across all channels", page+1, pages, len(memos), total),
+		},
+	}
+	if len(memos) == 0 {
+		embed.Description = "No pending memos."
+	}
+
+	end := min((page+1)*listPageSize, len(memos))
+	for _, memo := range memos[page*listPageSize : end] {
+		var value strings.Builder
+		switch {
+		case scope == listScopeChannel && memo.DiscordUserID != userID:
+			value.WriteString(fmt.Sprintf("👤 <@%s>\n", memo.DiscordUserID))
+		case scope == listScopeAll:
+			value.WriteString(fmt.Sprintf("📍 <#%s>\n", memo.DiscordChannelID))
+		}
+		value.WriteString(describeRecurrence(memo))
+		value.WriteString(describeFailure(memo))
+		value.WriteString(fmt.Sprintf("📌 %s", memo.Content))
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("#%d · ⏰ %s", memo.ID, memo.RemindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST")),
+			Value: truncate(value.String(), maxFieldValueLength),
+		})
+	}
+
+	return &discordgo.InteractionResponseData{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: listComponents(scope, page, pages, showOthers),
+	}, nil
+}
+
+// listComponents builds the filter menu and the Previous / Next buttons. The
+// menu offers other members' memos only if showOthers is set.
+func listComponents(scope string, page, pages int, showOthers bool) []discordgo.MessageComponent {
+	var options []discordgo.SelectMenuOption
+	for _, sc := range listScopes {
+		if sc.value == listScopeChannel && !showOthers {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   sc.label,
+			Value:   sc.value,
+			Default: sc.value == scope,
+		})
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID: actionList,
+				Options:  options,
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Previous",
+				Style:    discordgo.SecondaryButton,
+				CustomID: fmt.Sprintf("%s:%s:%d", actionList, scope, page-1),
+				Emoji:    &discordgo.ComponentEmoji{Name: "◀️"},
+				Disabled: page == 0,
+			},
+			discordgo.Button{
+				Label:    "Next",
+				Style:    discordgo.SecondaryButton,
+				CustomID: fmt.Sprintf("%s:%s:%d", actionList, scope, page+1),
+				Emoji:    &discordgo.ComponentEmoji{Name: "▶️"},
+				Disabled: page >= pages-1,
+			},
+		}},
+	}
+}
+
+// listShowsOthers reports whether the server lets /list show other members' memos
+func (c *Client) listShowsOthers(ctx context.Context, guildID string) (bool, error) {
+	if guildID == "" {
+		return true, nil
+	}
+	settings, err := c.service.GuildSettings(ctx, guildID)
+	if err != nil {
+		return false, err
+	}
+	return settings.ListShowOthers, nil
+}
+
+func listScopeTitle(scope string) string {
+	for _, sc := range listScopes {
+		if sc.value == scope {
+			return sc.title
+		}
+	}
+	return "Your memos"
+}
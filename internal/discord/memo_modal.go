@@ -0,0 +1,73 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+
+	"memo-bot/internal/logging"
+	"memo-bot/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxMemoLength matches Discord's message limit minus room for the reminder header
+const maxMemoLength = 1800
+
+// openMemoModal shows the memo editor with a multi-line content field. Anything
+// already passed as /memo options is prefilled; the delivery choice and mention
+// targets travel in the modal's custom ID.
+func (c *Client) openMemoModal(ctx context.Context, s Session, i *discordgo.InteractionCreate, req memoRequest) {
+	content := truncate(req.content, maxMemoLength)
+
+	mentions, err := service.FormatMentions(req.mentions)
+	if err != nil {
+		c.respondEphemeral(ctx, s, i, fmt.Sprintf("❌ %s", err))
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("%s:%s:%s", modalMemo, req.deliver, mentions),
+			Title:    "New memo",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    inputContent,
+						Label:       "What to remind you about",
+						Style:       discordgo.TextInputParagraph,
+						Placeholder: "Checklists and multi-paragraph notes are fine",
+						Value:       content,
+						Required:    true,
+						MaxLength:   maxMemoLength,
+					},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    inputWhen,
+						Label:       "When",
+						Style:       discordgo.TextInputShort,
+						Placeholder: "in 2 hours, tomorrow at 3pm, 2024-03-07 15:30",
+						Value:       req.when,
+						Required:    true,
+						MaxLength:   100,
+					},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    inputRepeat,
+						Label:       "Repeat (optional)",
+						Style:       discordgo.TextInputShort,
+						Placeholder: "daily, weekdays, weekly, FREQ=WEEKLY;BYDAY=MO",
+						Value:       req.repeat,
+						Required:    false,
+						MaxLength:   200,
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error opening memo modal", logging.KeyError, err)
+	}
+}
@@ -0,0 +1,173 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"memo-bot/internal/db"
+	"memo-bot/internal/logging"
+	"memo-bot/internal/service"
+	"memo-bot/internal/timeutil"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// remindMessageCommand is the message context-menu command
+const remindMessageCommand = "Remind me about this"
+
+// Modal custom IDs have the form "<modal>:<arg>"; inputs use fixed IDs
+const (
+	modalRemindMessage = "remind_message"
+	modalMemo          = "memo"
+	inputWhen          = "when"
+	inputNote          = "note"
+	inputContent       = "content"
+	inputRepeat        = "repeat"
+)
+
+// maxNoteLength keeps the prefilled note well inside Discord's text input limit
+const maxNoteLength = 1000
+
+// handleMessageCommand opens the "when" modal for the message-context-menu command
+func (c *Client) handleMessageCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	if data.Name != remindMessageCommand {
+		return
+	}
+	if err := c.checkMemoRateLimit(i); err != nil {
+		c.respondEphemeral(ctx, s, i, fmt.Sprintf("❌ %s", err))
+		return
+	}
+
+	note := ""
+	if data.Resolved != nil {
+		if msg, ok := data.Resolved.Messages[data.TargetID]; ok {
+			note = msg.Content
+		}
+	}
+	note = truncate(note, maxNoteLength)
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("%s:%s", modalRemindMessage, data.TargetID),
+			Title:    "Remind me about this message",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    inputWhen,
+						Label:       "When",
+						Style:       discordgo.TextInputShort,
+						Placeholder: "in 2 hours, tomorrow at 3pm, 2024-03-07 15:30",
+						Required:    true,
+						MaxLength:   100,
+					},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:  inputNote,
+						Label:     "Note",
+						Style:     discordgo.TextInputParagraph,
+						Value:     note,
+						Required:  false,
+						MaxLength: maxNoteLength,
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error opening modal", logging.KeyError, err)
+	}
+}
+
+// handleModalSubmit routes submitted modals and replies ephemerally with the outcome
+func (c *Client) handleModalSubmit(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
+	data := i.ModalSubmitData()
+	modal, arg, _ := strings.Cut(data.CustomID, ":")
+
+	var response string
+	var err error
+	switch modal {
+	case modalRemindMessage:
+		response, err = c.handleRemindMessageSubmit(ctx, i, arg, modalValues(data))
+	case modalMemo:
+		values := modalValues(data)
+		deliver, mentions, _ := strings.Cut(arg, ":")
+		response, err = c.createMemo(ctx, i, memoRequest{
+			content:  values[inputContent],
+			when:     values[inputWhen],
+			repeat:   values[inputRepeat],
+			deliver:  deliver,
+			mentions: service.ParseMentions(mentions),
+		})
+	default:
+		return
+	}
+
+	if err != nil {
+		response = fmt.Sprintf("❌ %s", err)
+	}
+	c.respondEphemeral(ctx, s, i, response)
+}
+
+func (c *Client) handleRemindMessageSubmit(ctx context.Context, i *discordgo.InteractionCreate, messageID string, values map[string]string) (string, error) {
+	userID := interactionUserID(i)
+
+	remindAt, err := timeutil.ParseTime(values[inputWhen], c.userTimezone(ctx, userID, i.GuildID), c.clock)
+	if err != nil {
+		return "", fmt.Errorf("invalid time format (case-insensitive). Examples:\n- today at 3pm\n- tomorrow at 3pm\n- in 2 hours\n- next monday at 15:00\n- 2024-03-07 15:30")
+	}
+	if remindAt.Before(c.clock.Now()) {
+		return "", fmt.Errorf("memo time must be in the future")
+	}
+
+	content := strings.TrimSpace(values[inputNote])
+	if content == "" {
+		content = "this message"
+	}
+
+	_, err = c.service.CreateMemo(ctx, userID, i.ChannelID, content, remindAt, service.MemoOptions{
+		GuildID:         i.GuildID,
+		SourceMessageID: messageID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	loc := c.userLocation(ctx, userID, i.GuildID)
+	return fmt.Sprintf("✅ I'll remind you about [this message](%s)\n⏰ %s",
+		jumpLink(i.GuildID, i.ChannelID, messageID),
+		remindAt.In(loc).Format("Monday, January 2, 2006 at 15:04 MST")), nil
+}
+
+// modalValues flattens the text inputs of a submitted modal into customID -> value
+func modalValues(data discordgo.ModalSubmitInteractionData) map[string]string {
+	values := make(map[string]string)
+	for _, row := range data.Components {
+		actionsRow, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actionsRow.Components {
+			if input, ok := component.(*discordgo.TextInput); ok {
+				values[input.CustomID] = input.Value
+			}
+		}
+	}
+	return values
+}
+
+// messageLink returns the jump link to the message a memo was created from
+func messageLink(memo db.Memo) string {
+	return jumpLink(memo.GuildID.String, memo.DiscordChannelID, memo.SourceMessageID.String)
+}
+
+// jumpLink builds a Discord message URL; DMs use "@me" in place of a guild ID
+func jumpLink(guildID, channelID, messageID string) string {
+	if guildID == "" {
+		guildID = "@me"
+	}
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, channelID, messageID)
+}
@@ -0,0 +1,185 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+
+	"memo-bot/internal/db"
+	"memo-bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// manageMessagesPermission hides moderator commands from regular members
+var manageMessagesPermission int64 = discordgo.PermissionManageMessages
+
+var reassignCommand = &discordgo.ApplicationCommand{
+	Name:                     "reassign",
+	Description:              "Hand a member's memo over to someone else (moderators)",
+	DefaultMemberPermissions: &manageMessagesPermission,
+	DMPermission:             &guildOnly,
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "id",
+			Description: "The ID of the memo to reassign",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "user",
+			Description: "The member who should own the memo",
+			Required:    true,
+		},
+	},
+}
+
+var purgeCommand = &discordgo.ApplicationCommand{
+	Name:                     "purge",
+	Description:              "Delete every pending memo of a member in this server, e.g. after they left",
+	DefaultMemberPermissions: &manageServerPermission,
+	DMPermission:             &guildOnly,
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "user",
+			Description: "The member whose memos to delete",
+			Required:    true,
+		},
+	},
+}
+
+// checkModerator lets members with Manage Messages act on other members'
+// memos. Interactions only carry the member's permissions in the channel they
+// were used in, so moderators act from the channel the memo is posted in.
+func checkModerator(i *discordgo.InteractionCreate, memo db.Memo, verb string) error {
+	if i.Member == nil || i.Member.Permissions&discordgo.PermissionManageMessages == 0 ||
+		(memo.GuildID.Valid && memo.GuildID.String != i.GuildID) {
+		return fmt.Errorf("memo #%d belongs to <@%s>. You can only %s your own memos", memo.ID, memo.DiscordUserID, verb)
+	}
+	if memo.DiscordChannelID != i.ChannelID {
+		return fmt.Errorf("memo #%d is posted in <#%s>. Use this command there to %s it as a moderator", memo.ID, memo.DiscordChannelID, verb)
+	}
+	return nil
+}
+
+func (c *Client) handleDeleteCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) (string, error) {
+	memoID := i.ApplicationCommandData().Options[0].IntValue()
+	userID := interactionUserID(i)
+
+	// First check if the memo exists and who may delete it
+	memo, err := c.service.GetMemo(ctx, int32(memoID))
+	if err != nil {
+		return "", err
+	}
+
+	if memo.DiscordUserID == userID {
+		if err := c.service.DeleteMemo(ctx, memo.ID, userID); err != nil {
+			return "", fmt.Errorf("failed to delete memo: %v", err)
+		}
+		return "✅ Memo deleted successfully!", nil
+	}
+
+	if err := checkModerator(i, *memo, "delete"); err != nil {
+		return "", err
+	}
+	if err := c.service.DeleteMemoAsModerator(ctx, *memo, userID); err != nil {
+		return "", fmt.Errorf("failed to delete memo: %v", err)
+	}
+	logging.FromContext(ctx).Info("Moderator deleted memo", logging.KeyMemoID, memo.ID, "owner_id", memo.DiscordUserID)
+
+	return fmt.Sprintf("✅ Memo #%d of <@%s> deleted", memo.ID, memo.DiscordUserID), nil
+}
+
+func (c *Client) handleReassignCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) (string, error) {
+	data := i.ApplicationCommandData()
+	options := optionMap(data.Options)
+	memoID := options["id"].IntValue()
+	newOwnerID := options["user"].UserValue(nil).ID
+
+	if data.Resolved != nil {
+		if user, ok := data.Resolved.Users[newOwnerID]; ok && user.Bot {
+			return "", fmt.Errorf("memos can't be assigned to bots")
+		}
+	}
+
+	memo, err := c.service.GetMemo(ctx, int32(memoID))
+	if err != nil {
+		return "", err
+	}
+	if err := checkModerator(i, *memo, "reassign"); err != nil {
+		return "", err
+	}
+
+	updated, err := c.service.ReassignMemo(ctx, memo.ID, newOwnerID, interactionUserID(i))
+	if err != nil {
+		return "", err
+	}
+	logging.FromContext(ctx).Info("Moderator reassigned memo", logging.KeyMemoID, memo.ID,
+		"owner_id", memo.DiscordUserID, "new_owner_id", newOwnerID)
+
+	return fmt.Sprintf("✅ Memo #%d reassigned from <@%s> to <@%s>", updated.ID, memo.DiscordUserID, updated.DiscordUserID), nil
+}
+
+// handlePurgeCommand deletes every pending memo of a member in the server.
+// Like /config it's hidden from members without Manage Server, and checked again here.
+func (c *Client) handlePurgeCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) (string, error) {
+	if i.GuildID == "" || i.Member == nil {
+		return "", fmt.Errorf("/purge can only be used in a server")
+	}
+	if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		return "", fmt.Errorf("you need the Manage Server permission to purge memos")
+	}
+
+	userID := optionMap(i.ApplicationCommandData().Options)["user"].UserValue(nil).ID
+	legacyChannelIDs, unresolved, err := c.legacyMemoChannels(ctx, s, i.GuildID, userID)
+	if err != nil {
+		return "", err
+	}
+	count, err := c.service.PurgeUserMemos(ctx, i.GuildID, userID, interactionUserID(i), legacyChannelIDs)
+	if err != nil {
+		return "", err
+	}
+
+	var note string
+	if unresolved > 0 {
+		note = fmt.Sprintf("\n⚠️ %d older memo(s) of <@%s> are in channels I can't look up anymore, so I can't tell if they're from this server. They were kept.", unresolved, userID)
+	}
+	if count == 0 {
+		return fmt.Sprintf("<@%s> has no pending memos in this server%s", userID, note), nil
+	}
+	logging.FromContext(ctx).Info("Moderator purged memos", "owner_id", userID, "count", count, "unresolved", unresolved)
+
+	return fmt.Sprintf("🧹 Deleted %d pending memo(s) of <@%s>%s", count, userID, note), nil
+}
+
+// legacyMemoChannels finds the channels of guildID holding userID's pending
+// memos from before memos recorded their server. It also returns how many of
+// those memos are in channels that can't be looked up, e.g. deleted ones.
+func (c *Client) legacyMemoChannels(ctx context.Context, s Session, guildID, userID string) ([]string, int, error) {
+	memos, err := c.service.ListUserPendingMemos(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	perChannel := make(map[string]int)
+	for _, memo := range memos {
+		if !memo.GuildID.Valid {
+			perChannel[memo.DiscordChannelID]++
+		}
+	}
+
+	var channelIDs []string
+	unresolved := 0
+	for channelID, n := range perChannel {
+		ch, err := s.Channel(channelID)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Error looking up the channel of memos without a server", logging.KeyChannelID, channelID, logging.KeyError, err)
+			unresolved += n
+			continue
+		}
+		if ch.GuildID == guildID {
+			channelIDs = append(channelIDs, channelID)
+		}
+	}
+	return channelIDs, unresolved, nil
+}
@@ -0,0 +1,34 @@
+package discord
+
+import (
+	"fmt"
+	"time"
+
+	"memo-bot/internal/metrics"
+	"memo-bot/internal/ratelimit"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// SetMemoRateLimit throttles how often each user can start creating a memo,
+// with /memo or the "Remind me about this" message command. There is no limit
+// unless one is set.
+func (c *Client) SetMemoRateLimit(limiter *ratelimit.Limiter) {
+	c.memoLimiter = limiter
+}
+
+// checkMemoRateLimit takes a token from the user's bucket, or says how long
+// to wait for the next one
+func (c *Client) checkMemoRateLimit(i *discordgo.InteractionCreate) error {
+	if c.memoLimiter == nil {
+		return nil
+	}
+	ok, wait := c.memoLimiter.Allow(interactionUserID(i))
+	if ok {
+		return nil
+	}
+	metrics.MemosRejected.WithLabelValues(metrics.RejectedRateLimit).Inc()
+	// Round up so a wait of 1.5s doesn't read as "try again in 1s"
+	wait = (wait + time.Second - 1).Truncate(time.Second)
+	return fmt.Errorf("you're creating memos too quickly. Try again in %s", wait)
+}
@@ -0,0 +1,214 @@
+package discord
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"memo-bot/internal/db"
+	"memo-bot/internal/service"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Settings that /config reset can restore to their default
+const (
+	settingTimezone        = "timezone"
+	settingDeliveryChannel = "delivery_channel"
+	settingListOthers      = "list_others"
+	settingMaxPending      = "max_pending"
+	settingAll             = "all"
+)
+
+var (
+	// manageServerPermission hides /config from members who can't manage the server
+	manageServerPermission int64 = discordgo.PermissionManageServer
+	// guildOnly keeps a command out of direct messages
+	guildOnly = false
+	// minPendingLimit is the smallest value of /config set max_pending, 0 meaning no limit
+	minPendingLimit = 0.0
+)
+
+var configCommand = &discordgo.ApplicationCommand{
+	Name:                     "config",
+	Description:              "Configure the bot for this server",
+	DefaultMemberPermissions: &manageServerPermission,
+	DMPermission:             &guildOnly,
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "show",
+			Description: "Show this server's settings",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Change one or more of this server's settings",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         settingTimezone,
+					Description:  "Default timezone for members who haven't set their own, e.g. Europe/Berlin",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         settingDeliveryChannel,
+					Description:  "Channel where /memo reminders are posted instead of the channel they were created in",
+					Required:     false,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        settingListOthers,
+					Description: "Whether /list can show other members' memos",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        settingMaxPending,
+					Description: "Most pending memos one member can have in this server (0 for no limit)",
+					Required:    false,
+					MinValue:    &minPendingLimit,
+					MaxValue:    1000,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "reset",
+			Description: "Restore a setting to the bot's default",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "setting",
+					Description: "The setting to reset",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Default timezone", Value: settingTimezone},
+						{Name: "Delivery channel", Value: settingDeliveryChannel},
+						{Name: "List others' memos", Value: settingListOthers},
+						{Name: "Max pending memos", Value: settingMaxPending},
+						{Name: "Everything", Value: settingAll},
+					},
+				},
+			},
+		},
+	},
+}
+
+// handleConfigCommand shows or changes the settings of the server the command
+// was used in. Discord hides the command from members without Manage Server,
+// but server admins can override that, so the permission is checked again here.
+func (c *Client) handleConfigCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) (string, error) {
+	if i.GuildID == "" || i.Member == nil {
+		return "", fmt.Errorf("/config can only be used in a server")
+	}
+	if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		return "", fmt.Errorf("you need the Manage Server permission to configure the bot")
+	}
+
+	settings, err := c.service.GuildSettings(ctx, i.GuildID)
+	if err != nil {
+		return "", err
+	}
+
+	sub := i.ApplicationCommandData().Options[0]
+	switch sub.Name {
+	case "show":
+		return c.describeGuildSettings(settings), nil
+	case "set":
+		if len(sub.Options) == 0 {
+			return "", fmt.Errorf("nothing to change. Pick at least one setting")
+		}
+		options := optionMap(sub.Options)
+		if opt, ok := options[settingTimezone]; ok {
+			settings.Timezone = sql.NullString{String: opt.StringValue(), Valid: true}
+		}
+		if opt, ok := options[settingDeliveryChannel]; ok {
+			settings.DeliveryChannelID = sql.NullString{String: opt.ChannelValue(nil).ID, Valid: true}
+		}
+		if opt, ok := options[settingListOthers]; ok {
+			settings.ListShowOthers = opt.BoolValue()
+		}
+		if opt, ok := options[settingMaxPending]; ok {
+			settings.MaxPendingPerUser = int32(opt.IntValue())
+		}
+	case "reset":
+		settings = resetGuildSetting(settings, optionMap(sub.Options)["setting"].StringValue())
+	default:
+		return "", fmt.Errorf("unknown subcommand %q", sub.Name)
+	}
+
+	if err := c.service.SaveGuildSettings(ctx, settings); err != nil {
+		return "", err
+	}
+	return "✅ Settings saved\n" + c.describeGuildSettings(settings), nil
+}
+
+// resetGuildSetting restores one setting, or all of them, to the default
+func resetGuildSetting(settings db.GuildSetting, setting string) db.GuildSetting {
+	defaults := service.DefaultGuildSettings(settings.GuildID)
+	switch setting {
+	case settingTimezone:
+		settings.Timezone = defaults.Timezone
+	case settingDeliveryChannel:
+		settings.DeliveryChannelID = defaults.DeliveryChannelID
+	case settingListOthers:
+		settings.ListShowOthers = defaults.ListShowOthers
+	case settingMaxPending:
+		settings.MaxPendingPerUser = defaults.MaxPendingPerUser
+	case settingAll:
+		settings = defaults
+	}
+	return settings
+}
+
+// describeGuildSettings lists a server's settings, naming the fallback of unset ones
+func (c *Client) describeGuildSettings(settings db.GuildSetting) string {
+	var b strings.Builder
+	b.WriteString("⚙️ **Server settings**\n")
+
+	if settings.Timezone.Valid {
+		b.WriteString(fmt.Sprintf("🌍 Default timezone: **%s**\n", settings.Timezone.String))
+	} else {
+		b.WriteString(fmt.Sprintf("🌍 Default timezone: %s (bot default)\n", c.timezone))
+	}
+
+	if settings.DeliveryChannelID.Valid {
+		b.WriteString(fmt.Sprintf("📍 Delivery channel: <#%s>\n", settings.DeliveryChannelID.String))
+	} else {
+		b.WriteString("📍 Delivery channel: the channel each memo was created in\n")
+	}
+
+	if settings.ListShowOthers {
+		b.WriteString("👥 `/list` can show other members' memos\n")
+	} else {
+		b.WriteString("👥 `/list` only shows members their own memos\n")
+	}
+
+	if settings.MaxPendingPerUser > 0 {
+		b.WriteString(fmt.Sprintf("📦 Max pending memos per member: **%d**", settings.MaxPendingPerUser))
+	} else {
+		b.WriteString("📦 Max pending memos per member: no limit")
+	}
+	return b.String()
+}
+
+// deliveryChannel returns where a new /memo in the interaction's channel is
+// posted: the server's delivery channel if one is configured
+func (c *Client) deliveryChannel(ctx context.Context, i *discordgo.InteractionCreate) (string, error) {
+	if i.GuildID == "" {
+		return i.ChannelID, nil
+	}
+	settings, err := c.service.GuildSettings(ctx, i.GuildID)
+	if err != nil {
+		return "", err
+	}
+	if settings.DeliveryChannelID.Valid {
+		return settings.DeliveryChannelID.String, nil
+	}
+	return i.ChannelID, nil
+}
@@ -0,0 +1,56 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"memo-bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// userTimezone returns the user's preferred timezone, falling back to the
+// server's default and then the global one
+func (c *Client) userTimezone(ctx context.Context, userID, guildID string) string {
+	tz, err := c.service.Timezone(ctx, userID, guildID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error loading user timezone", logging.KeyUserID, userID, logging.KeyError, err)
+		return c.timezone
+	}
+	if tz == "" {
+		return c.timezone
+	}
+	return tz
+}
+
+// userLocation loads the user's timezone as a *time.Location
+func (c *Client) userLocation(ctx context.Context, userID, guildID string) *time.Location {
+	loc, err := time.LoadLocation(c.userTimezone(ctx, userID, guildID))
+	if err != nil {
+		logging.FromContext(ctx).Warn("Error loading timezone, falling back to Local", logging.KeyError, err)
+		loc = time.Local
+	}
+	return loc
+}
+
+func (c *Client) handleTimezoneCommand(ctx context.Context, s Session, i *discordgo.InteractionCreate) (string, error) {
+	sub := i.ApplicationCommandData().Options[0]
+	userID := interactionUserID(i)
+
+	switch sub.Name {
+	case "set":
+		name := optionMap(sub.Options)["name"].StringValue()
+		if err := c.service.SetUserTimezone(ctx, userID, interactionUsername(i), name); err != nil {
+			return "", err
+		}
+		now := c.clock.Now().In(c.userLocation(ctx, userID, i.GuildID))
+		return fmt.Sprintf("✅ Your timezone is now **%s** (currently %s)", name, now.Format("15:04 MST")), nil
+	case "show":
+		tz := c.userTimezone(ctx, userID, i.GuildID)
+		now := c.clock.Now().In(c.userLocation(ctx, userID, i.GuildID))
+		return fmt.Sprintf("🌍 Your timezone is **%s** (currently %s)", tz, now.Format("15:04 MST")), nil
+	}
+
+	return "", fmt.Errorf("unknown subcommand %q", sub.Name)
+}
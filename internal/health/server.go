@@ -0,0 +1,99 @@
+// Package health serves the liveness, readiness and metrics endpoints used by
+// Docker health checks and Prometheus.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"memo-bot/internal/logging"
+	"memo-bot/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// readyTimeout bounds a readiness probe so a hung database fails the probe
+// instead of blocking it
+const readyTimeout = 2 * time.Second
+
+// Check reports an error when a dependency of the bot isn't usable
+type Check func(ctx context.Context) error
+
+// Server exposes /healthz, /readyz and /metrics
+type Server struct {
+	http   *http.Server
+	checks map[string]Check
+}
+
+// NewServer creates a server listening on addr. Every check must pass for
+// /readyz to report ready.
+func NewServer(addr string, checks map[string]Check) *Server {
+	s := &Server{checks: checks}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+
+	s.http = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Handler returns the server's routes, for tests
+func (s *Server) Handler() http.Handler {
+	return s.http.Handler
+}
+
+// Start serves in the background until Shutdown is called
+func (s *Server) Start() {
+	go func() {
+		if err := s.http.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Health server stopped", logging.KeyError, err)
+		}
+	}()
+}
+
+// Shutdown stops the server, waiting for in-flight requests
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+// handleHealthz reports that the process is alive and serving requests
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// handleReadyz runs every check and lists the failing ones
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	status := http.StatusOK
+	body := ""
+	names := make([]string, 0, len(s.checks))
+	for name := range s.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := s.checks[name](ctx); err != nil {
+			status = http.StatusServiceUnavailable
+			body += fmt.Sprintf("%s: %v\n", name, err)
+		}
+	}
+	if status == http.StatusOK {
+		body = "ok\n"
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEndpoints(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name       string
+		path       string
+		checks     map[string]Check
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "healthz ignores checks",
+			path:       "/healthz",
+			checks:     map[string]Check{"database": down},
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			name:       "ready when every check passes",
+			path:       "/readyz",
+			checks:     map[string]Check{"database": ok, "discord": ok},
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+		},
+		{
+			name:       "not ready lists failing checks",
+			path:       "/readyz",
+			checks:     map[string]Check{"database": down, "discord": ok},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "database: connection refused",
+		},
+		{
+			name:       "metrics",
+			path:       "/metrics",
+			wantStatus: http.StatusOK,
+			wantBody:   "memobot_memos_created_total",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewServer(":0", tt.checks).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s body = %q, want it to contain %q", tt.path, rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
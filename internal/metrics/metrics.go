@@ -0,0 +1,67 @@
+// Package metrics defines the bot's Prometheus metrics. They are registered on
+// Registry, which the HTTP server exposes at /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Registry holds the bot's metrics plus the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+// Reasons a memo creation is rejected, the reason label of MemosRejected
+const (
+	RejectedQuota     = "quota"
+	RejectedRateLimit = "rate_limit"
+)
+
+var (
+	MemosCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "memobot_memos_created_total",
+		Help: "Memos created.",
+	})
+	MemosDeleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "memobot_memos_deleted_total",
+		Help: "Memos deleted by their owner or a moderator.",
+	})
+	// MemosRejected counts memo creations refused by a limit, labelled by reason
+	MemosRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "memobot_memos_rejected_total",
+		Help: "Memo creations refused by a quota or the rate limit.",
+	}, []string{"reason"})
+	RemindersSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "memobot_reminders_sent_total",
+		Help: "Reminders delivered to Discord.",
+	})
+	// RemindersFailed is labelled permanent="true" for failures that won't be retried
+	RemindersFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "memobot_reminders_failed_total",
+		Help: "Reminder delivery attempts that failed.",
+	}, []string{"permanent"})
+	ScanDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "memobot_scan_duration_seconds",
+		Help:    "Time taken to claim and deliver due reminders.",
+		Buckets: prometheus.DefBuckets,
+	})
+	// DeliveryLag is how late a reminder was delivered, now - remind_at
+	DeliveryLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "memobot_delivery_lag_seconds",
+		Help:    "Delay between a reminder's scheduled time and its delivery.",
+		Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 300, 900, 3600},
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		MemosCreated,
+		MemosDeleted,
+		MemosRejected,
+		RemindersSent,
+		RemindersFailed,
+		ScanDuration,
+		DeliveryLag,
+	)
+}
@@ -0,0 +1,250 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "test.db")+"?_time_format=sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func newSQLiteRunner(t *testing.T) (*Runner, *sql.DB) {
+	t.Helper()
+	conn := openTestDB(t)
+	r, err := New(conn, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, conn
+}
+
+// fakeRunner runs migrations from fsys instead of the embedded ones
+func fakeRunner(t *testing.T, fsys fstest.MapFS) (*Runner, *sql.DB) {
+	t.Helper()
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := openTestDB(t)
+	return &Runner{db: conn, driver: "sqlite", dialect: dialects["sqlite"], migrations: migrations}, conn
+}
+
+func tableExists(t *testing.T, conn *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	err := conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+func appliedVersions(t *testing.T, conn *sql.DB) []int64 {
+	t.Helper()
+	rows, err := conn.Query(`SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var versions []int64
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func TestUp(t *testing.T) {
+	ctx := context.Background()
+	r, conn := newSQLiteRunner(t)
+
+	applied, err := r.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != len(r.migrations) {
+		t.Fatalf("Up() applied %d migrations, want all %d", len(applied), len(r.migrations))
+	}
+	for i, m := range applied {
+		if m.Version != int64(i+1) {
+			t.Fatalf("migration %d applied has version %d, want them in order", i, m.Version)
+		}
+	}
+	for _, table := range []string{"memos", "users", "guild_settings", "memo_moderation_log"} {
+		if !tableExists(t, conn, table) {
+			t.Errorf("table %s missing after Up()", table)
+		}
+	}
+	if got := appliedVersions(t, conn); len(got) != len(r.migrations) {
+		t.Errorf("schema_migrations has versions %v, want %d of them", got, len(r.migrations))
+	}
+
+	// Running again finds nothing to do
+	applied, err = r.Up(ctx)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("second Up() = %d migrations, %v; want none", len(applied), err)
+	}
+}
+
+func TestDown(t *testing.T) {
+	ctx := context.Background()
+	r, conn := newSQLiteRunner(t)
+	if _, err := r.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	last := r.migrations[len(r.migrations)-1]
+
+	m, err := r.Down(ctx)
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if m == nil || m.Version != last.Version {
+		t.Fatalf("Down() rolled back %v, want version %d", m, last.Version)
+	}
+	if tableExists(t, conn, "memo_moderation_log") {
+		t.Error("memo_moderation_log still exists after rolling back its migration")
+	}
+	if !tableExists(t, conn, "guild_settings") {
+		t.Error("guild_settings was dropped, only the last migration should be rolled back")
+	}
+	if got := appliedVersions(t, conn); len(got) != len(r.migrations)-1 || got[len(got)-1] == last.Version {
+		t.Errorf("schema_migrations has versions %v after Down()", got)
+	}
+
+	// Up reapplies only the rolled back migration
+	applied, err := r.Up(ctx)
+	if err != nil || len(applied) != 1 || applied[0].Version != last.Version {
+		t.Fatalf("Up() after Down() = %v, %v; want only version %d", applied, err, last.Version)
+	}
+}
+
+func TestDownWithNothingApplied(t *testing.T) {
+	r, _ := newSQLiteRunner(t)
+	m, err := r.Down(context.Background())
+	if err != nil || m != nil {
+		t.Fatalf("Down() on an empty database = %v, %v; want nothing", m, err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newSQLiteRunner(t)
+	if _, err := r.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(statuses) != len(r.migrations) {
+		t.Fatalf("Status() listed %d migrations, want %d", len(statuses), len(r.migrations))
+	}
+	for i, s := range statuses {
+		wantApplied := i < len(statuses)-1
+		if s.Version != r.migrations[i].Version || s.Name != r.migrations[i].Name {
+			t.Errorf("status %d is %04d_%s, want %04d_%s", i, s.Version, s.Name, r.migrations[i].Version, r.migrations[i].Name)
+		}
+		if s.Applied != wantApplied {
+			t.Errorf("%04d_%s applied = %v, want %v", s.Version, s.Name, s.Applied, wantApplied)
+		}
+		if s.Applied == s.AppliedAt.IsZero() {
+			t.Errorf("%04d_%s applied = %v but applied at %v", s.Version, s.Name, s.Applied, s.AppliedAt)
+		}
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	r, conn := fakeRunner(t, fstest.MapFS{
+		"0001_first.up.sql":    {Data: []byte(`CREATE TABLE first (id INTEGER);`)},
+		"0002_broken.up.sql":   {Data: []byte(`CREATE TABLE second (id INTEGER); INSERT INTO missing VALUES (1);`)},
+		"0003_never.up.sql":    {Data: []byte(`CREATE TABLE third (id INTEGER);`)},
+		"0001_first.down.sql":  {Data: []byte(`DROP TABLE first;`)},
+		"0002_broken.down.sql": {Data: []byte(`DROP TABLE second;`)},
+	})
+
+	applied, err := r.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "migration 0002_broken failed") {
+		t.Fatalf("Up() error = %v, want the broken migration to fail", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Fatalf("Up() applied %v, want only the first migration", applied)
+	}
+	if tableExists(t, conn, "second") {
+		t.Error("the failed migration's table was kept")
+	}
+	if tableExists(t, conn, "third") {
+		t.Error("migrations after the failed one were applied")
+	}
+	if got := appliedVersions(t, conn); len(got) != 1 || got[0] != 1 {
+		t.Errorf("schema_migrations has versions %v, want [1]", got)
+	}
+}
+
+func TestDownWithoutDownFile(t *testing.T) {
+	ctx := context.Background()
+	r, _ := fakeRunner(t, fstest.MapFS{
+		"0001_first.up.sql": {Data: []byte(`CREATE TABLE first (id INTEGER);`)},
+	})
+	if _, err := r.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Down(ctx); err == nil || !strings.Contains(err.Error(), "has no down file") {
+		t.Fatalf("Down() error = %v, want a missing down file", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "bad file name",
+			fsys:    fstest.MapFS{"first.up.sql": {Data: []byte(`SELECT 1;`)}},
+			wantErr: "invalid migration file name",
+		},
+		{
+			name:    "down without up",
+			fsys:    fstest.MapFS{"0001_first.down.sql": {Data: []byte(`SELECT 1;`)}},
+			wantErr: "has no up file",
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"0001_first.up.sql":   {Data: []byte(`SELECT 1;`)},
+				"0001_other.down.sql": {Data: []byte(`SELECT 1;`)},
+			},
+			wantErr: "conflicting names",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.fsys); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
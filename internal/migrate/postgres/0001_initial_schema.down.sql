@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS memos;
+DROP TABLE IF EXISTS users;
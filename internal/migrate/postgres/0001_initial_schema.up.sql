@@ -1,3 +1,4 @@
+-- IF NOT EXISTS adopts databases created from the old schema.sql
 CREATE TABLE IF NOT EXISTS users (
     user_id VARCHAR(50) PRIMARY KEY,
     username VARCHAR(100) NOT NULL,
@@ -13,4 +14,4 @@ CREATE TABLE IF NOT EXISTS memos (
     remind_at TIMESTAMP WITH TIME ZONE NOT NULL,
     sent BOOLEAN DEFAULT FALSE,
     CONSTRAINT remind_at_check CHECK (remind_at > created_at)
-); 
\ No newline at end of file
+);
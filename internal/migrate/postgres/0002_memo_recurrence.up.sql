@@ -0,0 +1 @@
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS recurrence TEXT;
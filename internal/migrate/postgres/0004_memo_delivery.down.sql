@@ -0,0 +1,2 @@
+ALTER TABLE memos DROP CONSTRAINT IF EXISTS delivery_check;
+ALTER TABLE memos DROP COLUMN IF EXISTS delivery;
@@ -0,0 +1,3 @@
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS delivery VARCHAR(10) NOT NULL DEFAULT 'channel';
+ALTER TABLE memos DROP CONSTRAINT IF EXISTS delivery_check;
+ALTER TABLE memos ADD CONSTRAINT delivery_check CHECK (delivery IN ('channel', 'dm', 'both'));
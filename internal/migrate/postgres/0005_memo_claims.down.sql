@@ -0,0 +1,2 @@
+ALTER TABLE memos DROP COLUMN IF EXISTS claimed_until;
+ALTER TABLE memos DROP COLUMN IF EXISTS claimed_by;
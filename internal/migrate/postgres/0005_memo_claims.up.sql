@@ -0,0 +1,2 @@
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(100);
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+ALTER TABLE memos DROP COLUMN IF EXISTS failed;
+ALTER TABLE memos DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE memos DROP COLUMN IF EXISTS last_error;
+ALTER TABLE memos DROP COLUMN IF EXISTS attempts;
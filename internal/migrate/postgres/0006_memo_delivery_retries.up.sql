@@ -0,0 +1,4 @@
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS failed BOOLEAN NOT NULL DEFAULT FALSE;
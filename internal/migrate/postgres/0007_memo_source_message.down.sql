@@ -0,0 +1,2 @@
+ALTER TABLE memos DROP COLUMN IF EXISTS source_message_id;
+ALTER TABLE memos DROP COLUMN IF EXISTS guild_id;
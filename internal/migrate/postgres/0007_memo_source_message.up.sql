@@ -0,0 +1,2 @@
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS guild_id VARCHAR(50);
+ALTER TABLE memos ADD COLUMN IF NOT EXISTS source_message_id VARCHAR(50);
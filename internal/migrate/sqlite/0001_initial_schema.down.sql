@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS memos_pending_idx;
+DROP TABLE IF EXISTS memos;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,32 @@
+-- Timestamps are stored as UTC text in the format written by the driver's
+-- `_time_format=sqlite` option, so they compare correctly as strings.
+CREATE TABLE IF NOT EXISTS users (
+    user_id VARCHAR(50) PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    discord_channel_id VARCHAR(50),
+    timezone VARCHAR(64)
+);
+
+CREATE TABLE IF NOT EXISTS memos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    discord_user_id VARCHAR(50) NOT NULL,
+    discord_channel_id VARCHAR(50) NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    remind_at TIMESTAMP NOT NULL,
+    sent BOOLEAN DEFAULT FALSE,
+    recurrence TEXT,
+    delivery VARCHAR(10) NOT NULL DEFAULT 'channel',
+    claimed_by VARCHAR(100),
+    claimed_until TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP,
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    guild_id VARCHAR(50),
+    source_message_id VARCHAR(50),
+    CONSTRAINT remind_at_check CHECK (remind_at > created_at),
+    CONSTRAINT delivery_check CHECK (delivery IN ('channel', 'dm', 'both'))
+);
+
+CREATE INDEX IF NOT EXISTS memos_pending_idx ON memos (sent, remind_at);
@@ -0,0 +1 @@
+ALTER TABLE memos DROP COLUMN mentions;
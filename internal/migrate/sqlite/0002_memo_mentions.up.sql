@@ -0,0 +1,2 @@
+-- Users and roles pinged when the memo is delivered, e.g. "user:123,role:456"
+ALTER TABLE memos ADD COLUMN mentions TEXT;
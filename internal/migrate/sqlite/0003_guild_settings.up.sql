@@ -0,0 +1,9 @@
+-- Per-server overrides of the bot's defaults. NULL columns fall back to the
+-- global configuration; max_pending_per_user = 0 means no limit.
+CREATE TABLE IF NOT EXISTS guild_settings (
+    guild_id VARCHAR(50) PRIMARY KEY,
+    timezone VARCHAR(64),
+    delivery_channel_id VARCHAR(50),
+    list_show_others BOOLEAN NOT NULL DEFAULT TRUE,
+    max_pending_per_user INTEGER NOT NULL DEFAULT 0
+);
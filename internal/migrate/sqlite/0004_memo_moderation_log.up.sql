@@ -0,0 +1,13 @@
+-- Who deleted or reassigned someone else's memo. memo_id is not a foreign key
+-- because deleted memos are gone from memos.
+CREATE TABLE IF NOT EXISTS memo_moderation_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    memo_id INTEGER NOT NULL,
+    guild_id VARCHAR(50),
+    action VARCHAR(10) NOT NULL,
+    moderator_id VARCHAR(50) NOT NULL,
+    owner_id VARCHAR(50) NOT NULL,
+    new_owner_id VARCHAR(50),
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    CONSTRAINT action_check CHECK (action IN ('delete', 'reassign', 'purge'))
+);
@@ -0,0 +1,94 @@
+// Package ratelimit throttles actions per key, such as a user, with token buckets.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"memo-bot/internal/clock"
+)
+
+// Limiter keeps a token bucket per key. A bucket holds up to burst tokens,
+// each action takes one, and tokens are refilled at a steady rate.
+type Limiter struct {
+	mu    sync.Mutex
+	clock clock.Clock
+	// interval is the time it takes to refill one token
+	interval time.Duration
+	burst    int
+	buckets  map[string]*bucket
+	// lastSweep is when full buckets were last dropped
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a limiter that allows burst actions in a row per key and refills
+// perMinute of them every minute
+func New(perMinute, burst int, clk clock.Clock) *Limiter {
+	if perMinute < 1 {
+		perMinute = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		clock:     clk,
+		interval:  time.Minute / time.Duration(perMinute),
+		burst:     burst,
+		buckets:   make(map[string]*bucket),
+		lastSweep: clk.Now(),
+	}
+}
+
+// Allow takes a token from key's bucket. If the bucket is empty it returns
+// false and how long until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) * float64(l.interval))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) / float64(l.interval)
+		if b.tokens > float64(l.burst) {
+			b.tokens = float64(l.burst)
+		}
+	}
+	b.last = now
+}
+
+// sweep drops the buckets that have refilled completely, as a new bucket
+// behaves the same. It runs at most once per full refill so Allow stays cheap.
+func (l *Limiter) sweep(now time.Time) {
+	full := l.interval * time.Duration(l.burst)
+	if now.Sub(l.lastSweep) < full {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"memo-bot/internal/clock"
+)
+
+var testStart = time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+func TestAllowBurstThenRefill(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	l := New(6, 3, clk) // a token every 10s, 3 in a row
+
+	for n := 0; n < 3; n++ {
+		if ok, _ := l.Allow("alice"); !ok {
+			t.Fatalf("action %d refused within the burst", n+1)
+		}
+	}
+	ok, wait := l.Allow("alice")
+	if ok {
+		t.Fatal("action allowed past the burst")
+	}
+	if wait != 10*time.Second {
+		t.Errorf("wait = %v, want 10s", wait)
+	}
+
+	clk.Advance(4 * time.Second)
+	if _, wait := l.Allow("alice"); wait != 6*time.Second {
+		t.Errorf("wait after 4s = %v, want 6s", wait)
+	}
+
+	clk.Advance(6 * time.Second)
+	if ok, _ := l.Allow("alice"); !ok {
+		t.Fatal("action refused after a token was refilled")
+	}
+	if ok, _ := l.Allow("alice"); ok {
+		t.Fatal("only one token should have been refilled")
+	}
+}
+
+func TestAllowIsPerKey(t *testing.T) {
+	l := New(1, 1, clock.NewFake(testStart))
+
+	if ok, _ := l.Allow("alice"); !ok {
+		t.Fatal("alice's first action refused")
+	}
+	if ok, _ := l.Allow("alice"); ok {
+		t.Fatal("alice's second action allowed")
+	}
+	if ok, _ := l.Allow("bob"); !ok {
+		t.Fatal("bob was limited by alice's actions")
+	}
+}
+
+func TestRefillIsCappedAtBurst(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	l := New(60, 2, clk)
+
+	l.Allow("alice")
+	clk.Advance(time.Hour)
+
+	allowed := 0
+	for n := 0; n < 5; n++ {
+		if ok, _ := l.Allow("alice"); ok {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Errorf("allowed %d actions after a long pause, want the burst of 2", allowed)
+	}
+}
+
+func TestSweepDropsIdleBuckets(t *testing.T) {
+	clk := clock.NewFake(testStart)
+	l := New(60, 2, clk)
+
+	l.Allow("alice")
+	l.Allow("bob")
+	clk.Advance(time.Minute)
+	l.Allow("carol")
+
+	if len(l.buckets) != 1 {
+		t.Errorf("%d buckets kept, want only carol's", len(l.buckets))
+	}
+	if _, ok := l.buckets["carol"]; !ok {
+		t.Error("carol's bucket was dropped")
+	}
+}
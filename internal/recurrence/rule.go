@@ -0,0 +1,269 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base unit a rule repeats on
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxSearchDays bounds how far ahead Next looks for an occurrence
+const maxSearchDays = 366 * 50
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayOrder = []string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// Rule is the subset of an RFC 5545 RRULE supported by the bot
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	Until    time.Time
+}
+
+// Parse accepts a shorthand ("daily", "weekly", "weekdays", "monthly", "yearly")
+// or an RRULE such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"
+func Parse(input string) (*Rule, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+
+	switch strings.ToLower(s) {
+	case "daily", "every day":
+		return &Rule{Freq: Daily, Interval: 1}, nil
+	case "weekly", "every week":
+		return &Rule{Freq: Weekly, Interval: 1}, nil
+	case "weekdays", "every weekday":
+		return &Rule{
+			Freq:     Weekly,
+			Interval: 1,
+			ByDay:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		}, nil
+	case "monthly", "every month":
+		return &Rule{Freq: Monthly, Interval: 1}, nil
+	case "yearly", "every year":
+		return &Rule{Freq: Yearly, Interval: 1}, nil
+	}
+
+	return parseRRule(s)
+}
+
+func parseRRule(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	rule := &Rule{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("unsupported frequency %q, use DAILY, WEEKLY, MONTHLY or YEARLY", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("interval must be a positive number")
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("invalid weekday %q in BYDAY", code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("recurrence rule must include FREQ")
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	// A date-only UNTIL includes the whole day
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q, use YYYYMMDD or YYYYMMDDTHHMMSSZ", value)
+}
+
+// String returns the canonical RRULE form stored in the database
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, code := range weekdayOrder {
+			if r.hasDay(weekdayCodes[code]) {
+				days = append(days, code)
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe returns a short human readable summary, e.g. "every 2 weeks on Mon, Fri"
+func (r *Rule) Describe() string {
+	units := map[Frequency]string{Daily: "day", Weekly: "week", Monthly: "month", Yearly: "year"}
+
+	var b strings.Builder
+	if r.Interval > 1 {
+		b.WriteString(fmt.Sprintf("every %d %ss", r.Interval, units[r.Freq]))
+	} else {
+		b.WriteString("every " + units[r.Freq])
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, code := range weekdayOrder {
+			if day := weekdayCodes[code]; r.hasDay(day) {
+				days = append(days, day.String()[:3])
+			}
+		}
+		b.WriteString(" on " + strings.Join(days, ", "))
+	}
+	if !r.Until.IsZero() {
+		b.WriteString(" until " + r.Until.Format("2006-01-02"))
+	}
+	return b.String()
+}
+
+// Next returns the first occurrence strictly after `after`, keeping the wall-clock
+// time of `start` in loc. Occurrences missed while the bot was offline are skipped.
+// The boolean is false once the rule has no further occurrences.
+func (r *Rule) Next(start, after time.Time, loc *time.Location) (time.Time, bool) {
+	start = start.In(loc)
+	hour, min, sec := start.Clock()
+
+	for k := 1; k <= maxSearchDays; k++ {
+		day := start.AddDate(0, 0, k)
+		candidate := time.Date(day.Year(), day.Month(), day.Day(), hour, min, sec, 0, loc)
+
+		if !r.Until.IsZero() && candidate.After(r.Until) {
+			return time.Time{}, false
+		}
+		if !candidate.After(after) || !r.matches(start, candidate) {
+			continue
+		}
+		return candidate, true
+	}
+	return time.Time{}, false
+}
+
+// First returns the first occurrence at or after start, in loc. A series whose
+// requested start doesn't match the rule, e.g. weekdays from a Saturday, begins
+// on the first matching day at start's wall-clock time.
+func (r *Rule) First(start time.Time, loc *time.Location) (time.Time, bool) {
+	start = start.In(loc)
+	if !r.Until.IsZero() && start.After(r.Until) {
+		return time.Time{}, false
+	}
+	if r.matches(start, start) {
+		return start, true
+	}
+	return r.Next(start, start, loc)
+}
+
+func (r *Rule) matches(start, candidate time.Time) bool {
+	switch r.Freq {
+	case Daily:
+		if daysBetween(start, candidate)%r.Interval != 0 {
+			return false
+		}
+		return len(r.ByDay) == 0 || r.hasDay(candidate.Weekday())
+	case Weekly:
+		if daysBetween(weekStart(start), weekStart(candidate))/7%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return candidate.Weekday() == start.Weekday()
+		}
+		return r.hasDay(candidate.Weekday())
+	case Monthly:
+		months := (candidate.Year()-start.Year())*12 + int(candidate.Month()-start.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return candidate.Day() == start.Day()
+		}
+		return r.hasDay(candidate.Weekday())
+	case Yearly:
+		if (candidate.Year()-start.Year())%r.Interval != 0 {
+			return false
+		}
+		return candidate.Month() == start.Month() && candidate.Day() == start.Day()
+	}
+	return false
+}
+
+func (r *Rule) hasDay(day time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// daysBetween counts calendar days between two dates, ignoring DST shifts
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// weekStart returns the Monday of t's week (RFC 5545 default WKST=MO)
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,303 @@
+package recurrence
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     string
+		describe string
+		wantErr  string
+	}{
+		{input: "daily", want: "FREQ=DAILY", describe: "every day"},
+		{input: "Every Week", want: "FREQ=WEEKLY", describe: "every week"},
+		{input: "weekdays", want: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", describe: "every week on Mon, Tue, Wed, Thu, Fri"},
+		{input: " monthly ", want: "FREQ=MONTHLY", describe: "every month"},
+		{input: "yearly", want: "FREQ=YEARLY", describe: "every year"},
+		{input: "FREQ=DAILY;INTERVAL=3", want: "FREQ=DAILY;INTERVAL=3", describe: "every 3 days"},
+		{input: "rrule:freq=weekly;interval=2;byday=fr,mo", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", describe: "every 2 weeks on Mon, Fri"},
+		{input: "FREQ=MONTHLY;INTERVAL=1", want: "FREQ=MONTHLY", describe: "every month"},
+		{input: "FREQ=DAILY;UNTIL=20260305", want: "FREQ=DAILY;UNTIL=20260305T235959Z", describe: "every day until 2026-03-05"},
+		{input: "FREQ=DAILY;UNTIL=20260305T120000Z;", want: "FREQ=DAILY;UNTIL=20260305T120000Z", describe: "every day until 2026-03-05"},
+
+		{input: "", wantErr: "empty recurrence rule"},
+		{input: "hourly", wantErr: "invalid recurrence rule part"},
+		{input: "FREQ=HOURLY", wantErr: "unsupported frequency"},
+		{input: "FREQ=DAILY;INTERVAL=0", wantErr: "interval must be a positive number"},
+		{input: "FREQ=DAILY;INTERVAL=two", wantErr: "interval must be a positive number"},
+		{input: "FREQ=WEEKLY;BYDAY=MO,XX", wantErr: `invalid weekday "XX"`},
+		{input: "FREQ=DAILY;UNTIL=tomorrow", wantErr: "invalid UNTIL"},
+		{input: "FREQ=DAILY;COUNT=3", wantErr: "unsupported recurrence rule part"},
+		{input: "INTERVAL=2", wantErr: "must include FREQ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rule, err := Parse(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.input, got, tt.want)
+			}
+			if got := rule.Describe(); got != tt.describe {
+				t.Errorf("Parse(%q).Describe() = %q, want %q", tt.input, got, tt.describe)
+			}
+
+			// The stored form parses back to the same rule
+			again, err := Parse(rule.String())
+			if err != nil || again.String() != tt.want {
+				t.Errorf("Parse(%q) = %v, %v; want it to round-trip", rule.String(), again, err)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		after time.Time // defaults to start
+		loc   *time.Location
+		want  time.Time // zero when there is no next occurrence
+	}{
+		{
+			name:  "daily",
+			rule:  "daily",
+			start: utc(2026, time.March, 2, 9),
+			want:  utc(2026, time.March, 3, 9),
+		},
+		{
+			name:  "missed occurrences are skipped",
+			rule:  "daily",
+			start: utc(2026, time.March, 2, 9),
+			after: utc(2026, time.March, 10, 12),
+			want:  utc(2026, time.March, 11, 9),
+		},
+		{
+			name:  "every 3 days",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			start: utc(2026, time.March, 2, 9),
+			after: utc(2026, time.March, 5, 9),
+			want:  utc(2026, time.March, 8, 9),
+		},
+		{
+			name:  "weekly keeps the weekday",
+			rule:  "weekly",
+			start: utc(2026, time.March, 4, 9), // Wednesday
+			want:  utc(2026, time.March, 11, 9),
+		},
+		{
+			name:  "every 2 weeks",
+			rule:  "FREQ=WEEKLY;INTERVAL=2",
+			start: utc(2026, time.March, 2, 9),
+			want:  utc(2026, time.March, 16, 9),
+		},
+		{
+			name:  "BYDAY list within the week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			start: utc(2026, time.March, 2, 9), // Monday
+			want:  utc(2026, time.March, 6, 9),
+		},
+		{
+			name:  "BYDAY list skips the off week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			start: utc(2026, time.March, 2, 9),
+			after: utc(2026, time.March, 6, 9),
+			want:  utc(2026, time.March, 16, 9),
+		},
+		{
+			name:  "weekdays skip the weekend",
+			rule:  "weekdays",
+			start: utc(2026, time.March, 6, 9), // Friday
+			want:  utc(2026, time.March, 9, 9),
+		},
+		{
+			name:  "monthly on the 29th skips February",
+			rule:  "monthly",
+			start: utc(2026, time.January, 29, 9),
+			want:  utc(2026, time.March, 29, 9),
+		},
+		{
+			name:  "monthly on the 29th in a leap year",
+			rule:  "monthly",
+			start: utc(2028, time.January, 29, 9),
+			want:  utc(2028, time.February, 29, 9),
+		},
+		{
+			name:  "monthly on the 30th skips February",
+			rule:  "monthly",
+			start: utc(2026, time.January, 30, 9),
+			want:  utc(2026, time.March, 30, 9),
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			rule:  "monthly",
+			start: utc(2026, time.March, 31, 9),
+			want:  utc(2026, time.May, 31, 9),
+		},
+		{
+			name:  "every 3 months",
+			rule:  "FREQ=MONTHLY;INTERVAL=3",
+			start: utc(2026, time.January, 15, 9),
+			want:  utc(2026, time.April, 15, 9),
+		},
+		{
+			name:  "yearly on February 29th",
+			rule:  "yearly",
+			start: utc(2028, time.February, 29, 9),
+			want:  utc(2032, time.February, 29, 9),
+		},
+		{
+			name:  "before UNTIL",
+			rule:  "FREQ=DAILY;UNTIL=20260305",
+			start: utc(2026, time.March, 2, 9),
+			after: utc(2026, time.March, 4, 9),
+			want:  utc(2026, time.March, 5, 9),
+		},
+		{
+			name:  "past UNTIL",
+			rule:  "FREQ=DAILY;UNTIL=20260305",
+			start: utc(2026, time.March, 2, 9),
+			after: utc(2026, time.March, 5, 9),
+		},
+		{
+			name:  "UNTIL with a time",
+			rule:  "FREQ=DAILY;UNTIL=20260305T080000Z",
+			start: utc(2026, time.March, 2, 9),
+			after: utc(2026, time.March, 4, 9),
+		},
+		{
+			name:  "daily across spring forward keeps 9am local",
+			rule:  "daily",
+			start: time.Date(2026, time.March, 7, 9, 0, 0, 0, newYork), // EST, UTC-5
+			loc:   newYork,
+			want:  utc(2026, time.March, 8, 13), // EDT, UTC-4
+		},
+		{
+			name:  "weekly across fall back keeps 9am local",
+			rule:  "weekly",
+			start: time.Date(2026, time.October, 20, 9, 0, 0, 0, berlin), // CEST, UTC+2
+			loc:   berlin,
+			want:  utc(2026, time.October, 27, 8), // CET, UTC+1
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			after := tt.after
+			if after.IsZero() {
+				after = tt.start
+			}
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+
+			got, ok := rule.Next(tt.start, after, loc)
+			if tt.want.IsZero() {
+				if ok {
+					t.Fatalf("Next() = %s, want no further occurrence", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Next() found no occurrence, want %s", tt.want)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("Next() = %s, want %s", got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFirst(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		loc   *time.Location
+		want  time.Time // zero when there is no occurrence
+	}{
+		{name: "daily starts right away", rule: "daily", start: utc(time.March, 7, 9), want: utc(time.March, 7, 9)},
+		{name: "weekly starts right away", rule: "weekly", start: utc(time.March, 7, 9), want: utc(time.March, 7, 9)},
+		{name: "monthly starts right away", rule: "monthly", start: utc(time.March, 31, 9), want: utc(time.March, 31, 9)},
+		{name: "weekdays on a weekday", rule: "weekdays", start: utc(time.March, 6, 9), want: utc(time.March, 6, 9)}, // Friday
+		{name: "weekdays from a Saturday", rule: "weekdays", start: utc(time.March, 7, 9), want: utc(time.March, 9, 9)},
+		{name: "weekdays from a Sunday", rule: "weekdays", start: utc(time.March, 8, 9), want: utc(time.March, 9, 9)},
+		{name: "BYDAY later in the week", rule: "FREQ=WEEKLY;BYDAY=MO,FR", start: utc(time.March, 4, 9), want: utc(time.March, 6, 9)}, // Wednesday
+		{name: "BYDAY past the week's last day", rule: "FREQ=WEEKLY;BYDAY=MO,FR", start: utc(time.March, 7, 9), want: utc(time.March, 9, 9)},
+		{name: "BYDAY every 2 weeks skips the next week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", start: utc(time.March, 7, 9), want: utc(time.March, 16, 9)},
+		{name: "daily BYDAY", rule: "FREQ=DAILY;BYDAY=TU", start: utc(time.March, 4, 9), want: utc(time.March, 10, 9)},
+		{
+			// Friday 20:00 UTC is already Saturday in Tokyo
+			name:  "the weekday is the one in loc",
+			rule:  "weekdays",
+			start: utc(time.March, 6, 20),
+			loc:   tokyo,
+			want:  utc(time.March, 8, 20), // Monday 05:00 JST
+		},
+		{name: "UNTIL after the first match", rule: "FREQ=WEEKLY;BYDAY=MO;UNTIL=20260309", start: utc(time.March, 7, 9), want: utc(time.March, 9, 9)},
+		{name: "UNTIL before the first match", rule: "FREQ=WEEKLY;BYDAY=MO;UNTIL=20260308", start: utc(time.March, 7, 9)},
+		{name: "UNTIL before start", rule: "FREQ=DAILY;UNTIL=20260305", start: utc(time.March, 7, 9)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+
+			got, ok := rule.First(tt.start, loc)
+			if tt.want.IsZero() {
+				if ok {
+					t.Fatalf("First() = %s, want no occurrence", got)
+				}
+				return
+			}
+			if !ok || !got.Equal(tt.want) {
+				t.Fatalf("First() = %s, %v; want %s", got.UTC(), ok, tt.want)
+			}
+		})
+	}
+}
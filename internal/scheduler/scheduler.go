@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"memo-bot/internal/clock"
+	"memo-bot/internal/db"
+	"memo-bot/internal/logging"
+)
+
+// preloadLimit is how many upcoming memos are kept in memory. Later memos are
+// picked up when the heap is reloaded after each delivery round.
+const preloadLimit = 500
+
+// Source supplies the upcoming reminders the scheduler keeps in memory
+type Source interface {
+	UpcomingReminders(ctx context.Context, limit int32) ([]db.Memo, error)
+}
+
+// ProcessFunc delivers every reminder that is due
+type ProcessFunc func(ctx context.Context)
+
+// Scheduler wakes up exactly at the next memo's remind_at instead of polling.
+// The database stays the source of truth: the heap only decides when to run
+// process, and a periodic safety-net scan covers anything the heap missed.
+type Scheduler struct {
+	source   Source
+	process  ProcessFunc
+	interval time.Duration
+	clock    clock.Clock
+
+	mu    sync.Mutex
+	queue memoQueue
+	index map[int32]*item
+	wake  chan struct{}
+}
+
+// New creates a scheduler that runs process when memos come due and at least
+// every interval, with timers taken from clk
+func New(source Source, process ProcessFunc, interval time.Duration, clk clock.Clock) *Scheduler {
+	return &Scheduler{
+		source:   source,
+		process:  process,
+		interval: interval,
+		clock:    clk,
+		index:    make(map[int32]*item),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Run performs an initial catch-up scan, then blocks until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	slog.Info("Performing initial scan for missed reminders")
+	s.runProcess(ctx)
+	slog.Info("Initial scan completed")
+
+	safetyNet := s.clock.NewTicker(s.interval)
+	defer safetyNet.Stop()
+
+	for {
+		var timer clock.Timer
+		var fire <-chan time.Time
+		if next, ok := s.next(); ok {
+			timer = s.clock.NewTimer(next.Sub(s.clock.Now()))
+			fire = timer.C()
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			return
+		case <-s.wake:
+			// The earliest memo changed, recompute the timer
+		case <-fire:
+			s.runProcess(ctx)
+		case <-safetyNet.C():
+			slog.Debug("Running safety-net scan for reminders")
+			s.runProcess(ctx)
+		}
+		stopTimer(timer)
+	}
+}
+
+// Schedule adds or moves a memo in the in-memory schedule
+func (s *Scheduler) Schedule(memoID int32, remindAt time.Time) {
+	s.mu.Lock()
+	if it, ok := s.index[memoID]; ok {
+		it.at = remindAt
+		heap.Fix(&s.queue, it.pos)
+	} else {
+		it := &item{id: memoID, at: remindAt}
+		heap.Push(&s.queue, it)
+		s.index[memoID] = it
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Cancel removes a memo from the in-memory schedule
+func (s *Scheduler) Cancel(memoID int32) {
+	s.mu.Lock()
+	if it, ok := s.index[memoID]; ok {
+		heap.Remove(&s.queue, it.pos)
+		delete(s.index, memoID)
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+// runProcess delivers due reminders and reloads the heap from the database
+func (s *Scheduler) runProcess(ctx context.Context) {
+	started := s.clock.Now()
+	s.process(ctx)
+	s.reload(ctx, started)
+}
+
+// reload replaces the heap with the next upcoming memos. Memos that were already
+// due when the last round started were attempted in it; they are left to the
+// safety-net scan so a failing delivery can't spin the timer.
+func (s *Scheduler) reload(ctx context.Context, cutoff time.Time) {
+	memos, err := s.source.UpcomingReminders(ctx, preloadLimit)
+	if err != nil {
+		slog.Error("Error loading upcoming reminders", logging.KeyError, err)
+		return
+	}
+
+	queue := make(memoQueue, 0, len(memos))
+	index := make(map[int32]*item, len(memos))
+	for _, memo := range memos {
+		if !memo.RemindAt.After(cutoff) {
+			continue
+		}
+		it := &item{id: memo.ID, at: memo.RemindAt, pos: len(queue)}
+		queue = append(queue, it)
+		index[memo.ID] = it
+	}
+	heap.Init(&queue)
+
+	s.mu.Lock()
+	s.queue = queue
+	s.index = index
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return time.Time{}, false
+	}
+	return s.queue[0].at, true
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func stopTimer(t clock.Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
+
+type item struct {
+	id  int32
+	at  time.Time
+	pos int
+}
+
+// memoQueue is a min-heap of memos ordered by reminder time
+type memoQueue []*item
+
+func (q memoQueue) Len() int           { return len(q) }
+func (q memoQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+
+func (q memoQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].pos = i
+	q[j].pos = j
+}
+
+func (q *memoQueue) Push(x any) {
+	it := x.(*item)
+	it.pos = len(*q)
+	*q = append(*q, it)
+}
+
+func (q *memoQueue) Pop() any {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return it
+}
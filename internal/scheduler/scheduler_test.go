@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"memo-bot/internal/clock"
+	"memo-bot/internal/db"
+)
+
+var testStart = time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+type fakeSource struct {
+	mu    sync.Mutex
+	memos []db.Memo
+}
+
+func (f *fakeSource) UpcomingReminders(ctx context.Context, limit int32) ([]db.Memo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]db.Memo(nil), f.memos...), nil
+}
+
+// start runs a scheduler on a fake clock and reports each process call on the
+// returned channel with the fake time it ran at
+func start(t *testing.T, source Source, interval time.Duration) (*Scheduler, *clock.Fake, <-chan time.Time) {
+	t.Helper()
+	clk := clock.NewFake(testStart)
+	calls := make(chan time.Time, 10)
+	sched := New(source, func(ctx context.Context) { calls <- clk.Now() }, interval, clk)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sched.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return sched, clk, calls
+}
+
+// waitForWaiters blocks until the scheduler has armed n timers and tickers
+func waitForWaiters(t *testing.T, clk *clock.Fake, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for clk.Waiters() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("scheduler armed %d timers, want %d", clk.Waiters(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func expectCall(t *testing.T, calls <-chan time.Time, want time.Time) {
+	t.Helper()
+	select {
+	case got := <-calls:
+		if !got.Equal(want) {
+			t.Fatalf("process ran at %s, want %s", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("process didn't run, want a run at %s", want)
+	}
+}
+
+func expectNoCall(t *testing.T, calls <-chan time.Time) {
+	t.Helper()
+	select {
+	case got := <-calls:
+		t.Fatalf("process ran unexpectedly at %s", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestRunCatchesUpAtStartup(t *testing.T) {
+	source := &fakeSource{memos: []db.Memo{
+		{ID: 1, RemindAt: testStart.Add(-6 * time.Hour)},
+		{ID: 2, RemindAt: testStart.Add(-time.Minute)},
+	}}
+	_, _, calls := start(t, source, time.Hour)
+
+	// Missed reminders are delivered before any time passes
+	expectCall(t, calls, testStart)
+	expectNoCall(t, calls)
+}
+
+func TestRunWakesWhenMemoComesDue(t *testing.T) {
+	source := &fakeSource{memos: []db.Memo{
+		{ID: 1, RemindAt: testStart.Add(10 * time.Minute)},
+	}}
+	_, clk, calls := start(t, source, time.Hour)
+	expectCall(t, calls, testStart)
+
+	// Safety-net ticker and the timer for memo #1
+	waitForWaiters(t, clk, 2)
+	clk.Advance(9 * time.Minute)
+	expectNoCall(t, calls)
+
+	clk.Advance(time.Minute)
+	expectCall(t, calls, testStart.Add(10*time.Minute))
+}
+
+func TestScheduleRearmsTimer(t *testing.T) {
+	sched, clk, calls := start(t, &fakeSource{}, time.Hour)
+	expectCall(t, calls, testStart)
+	waitForWaiters(t, clk, 1)
+
+	sched.Schedule(7, testStart.Add(5*time.Minute))
+	waitForWaiters(t, clk, 2)
+	clk.Advance(5 * time.Minute)
+	expectCall(t, calls, testStart.Add(5*time.Minute))
+}
+
+func TestCancelDisarmsTimer(t *testing.T) {
+	source := &fakeSource{memos: []db.Memo{
+		{ID: 1, RemindAt: testStart.Add(10 * time.Minute)},
+	}}
+	sched, clk, calls := start(t, source, time.Hour)
+	expectCall(t, calls, testStart)
+	waitForWaiters(t, clk, 2)
+
+	sched.Cancel(1)
+	waitForWaiters(t, clk, 1)
+	clk.Advance(30 * time.Minute)
+	expectNoCall(t, calls)
+}
+
+func TestSafetyNetScan(t *testing.T) {
+	_, clk, calls := start(t, &fakeSource{}, time.Minute)
+	expectCall(t, calls, testStart)
+	waitForWaiters(t, clk, 1)
+
+	clk.Advance(time.Minute)
+	expectCall(t, calls, testStart.Add(time.Minute))
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"memo-bot/internal/db"
+	"memo-bot/internal/timeutil"
+)
+
+// maxPendingLimit caps the per-user limit a server can configure
+const maxPendingLimit = 1000
+
+// DefaultGuildSettings are the settings of a server that never ran /config
+func DefaultGuildSettings(guildID string) db.GuildSetting {
+	return db.GuildSetting{
+		GuildID:        guildID,
+		ListShowOthers: true,
+	}
+}
+
+// GuildSettings returns a server's settings, or the defaults if none are stored
+func (s *MemoService) GuildSettings(ctx context.Context, guildID string) (db.GuildSetting, error) {
+	settings, err := s.queries.GetGuildSettings(ctx, guildID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return DefaultGuildSettings(guildID), nil
+		}
+		return db.GuildSetting{}, fmt.Errorf("failed to get server settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveGuildSettings validates and stores a server's settings
+func (s *MemoService) SaveGuildSettings(ctx context.Context, settings db.GuildSetting) error {
+	if settings.GuildID == "" {
+		return fmt.Errorf("server settings can only be changed in a server")
+	}
+	if settings.Timezone.Valid && !timeutil.ValidTimezone(settings.Timezone.String) {
+		return fmt.Errorf("unknown timezone %q. Use an IANA name like Europe/Berlin", settings.Timezone.String)
+	}
+	if settings.MaxPendingPerUser < 0 || settings.MaxPendingPerUser > maxPendingLimit {
+		return fmt.Errorf("the pending memo limit must be between 0 (no limit) and %d", maxPendingLimit)
+	}
+
+	if err := s.queries.UpsertGuildSettings(ctx, db.UpsertGuildSettingsParams(settings)); err != nil {
+		return fmt.Errorf("failed to save server settings: %v", err)
+	}
+	return nil
+}
+
+// Timezone returns the timezone for a user in a server: their own preference,
+// else the server's default, else an empty string
+func (s *MemoService) Timezone(ctx context.Context, userID, guildID string) (string, error) {
+	tz, err := s.UserTimezone(ctx, userID)
+	if err != nil || tz != "" || guildID == "" {
+		return tz, err
+	}
+	settings, err := s.GuildSettings(ctx, guildID)
+	if err != nil {
+		return "", err
+	}
+	return settings.Timezone.String, nil
+}
+
+// fullGuildQuota returns the server's limit on pending memos per user if
+// userID reached it, or nil
+func (s *MemoService) fullGuildQuota(ctx context.Context, userID, guildID string) (*fullQuota, error) {
+	if guildID == "" {
+		return nil, nil
+	}
+	settings, err := s.GuildSettings(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.MaxPendingPerUser == 0 {
+		return nil, nil
+	}
+
+	count, err := s.queries.CountUserPendingMemosInGuild(ctx, db.CountUserPendingMemosInGuildParams{
+		DiscordUserID: userID,
+		GuildID:       nullString(guildID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reminders: %v", err)
+	}
+	if count >= int64(settings.MaxPendingPerUser) {
+		return &fullQuota{count: count, max: int(settings.MaxPendingPerUser), where: " in this server"}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"memo-bot/internal/db"
+)
+
+func TestSaveGuildSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings db.GuildSetting
+		wantErr  string
+	}{
+		{
+			name:     "valid settings",
+			settings: db.GuildSetting{GuildID: "guild-1", Timezone: sql.NullString{String: "Europe/Berlin", Valid: true}, MaxPendingPerUser: 10},
+		},
+		{
+			name:     "unknown timezone",
+			settings: db.GuildSetting{GuildID: "guild-1", Timezone: sql.NullString{String: "Mars/Olympus", Valid: true}},
+			wantErr:  "unknown timezone",
+		},
+		{
+			name:     "negative limit",
+			settings: db.GuildSetting{GuildID: "guild-1", MaxPendingPerUser: -1},
+			wantErr:  "pending memo limit",
+		},
+		{
+			name:     "outside a server",
+			settings: db.GuildSetting{},
+			wantErr:  "only be changed in a server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newTestService()
+			ctx := context.Background()
+
+			err := svc.SaveGuildSettings(ctx, tt.settings)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SaveGuildSettings() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SaveGuildSettings() error = %v", err)
+			}
+			got, err := svc.GuildSettings(ctx, tt.settings.GuildID)
+			if err != nil {
+				t.Fatalf("GuildSettings() error = %v", err)
+			}
+			if got != tt.settings {
+				t.Fatalf("GuildSettings() = %+v, want %+v", got, tt.settings)
+			}
+		})
+	}
+}
+
+func TestGuildSettingsDefaults(t *testing.T) {
+	svc, _, _ := newTestService()
+
+	got, err := svc.GuildSettings(context.Background(), "guild-1")
+	if err != nil {
+		t.Fatalf("GuildSettings() error = %v", err)
+	}
+	if got != DefaultGuildSettings("guild-1") || !got.ListShowOthers {
+		t.Fatalf("GuildSettings() = %+v, want the defaults", got)
+	}
+}
+
+func TestTimezoneFallsBackToGuild(t *testing.T) {
+	svc, _, _ := newTestService()
+	ctx := context.Background()
+	err := svc.SaveGuildSettings(ctx, db.GuildSetting{GuildID: "guild-1", Timezone: sql.NullString{String: "Asia/Tokyo", Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SetUserTimezone(ctx, "user-2", "user-2", "Europe/Paris"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		userID, guildID, want string
+	}{
+		{"user-1", "guild-1", "Asia/Tokyo"},
+		{"user-2", "guild-1", "Europe/Paris"},
+		{"user-1", "guild-2", ""},
+		{"user-1", "", ""},
+	}
+	for _, tt := range tests {
+		got, err := svc.Timezone(ctx, tt.userID, tt.guildID)
+		if err != nil {
+			t.Fatalf("Timezone(%q, %q) error = %v", tt.userID, tt.guildID, err)
+		}
+		if got != tt.want {
+			t.Errorf("Timezone(%q, %q) = %q, want %q", tt.userID, tt.guildID, got, tt.want)
+		}
+	}
+}
+
+func TestMaxPendingPerUser(t *testing.T) {
+	svc, _, clk := newTestService()
+	ctx := context.Background()
+	if err := svc.SaveGuildSettings(ctx, db.GuildSetting{GuildID: "guild-1", ListShowOthers: true, MaxPendingPerUser: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(userID, guildID string) error {
+		_, err := svc.CreateMemo(ctx, userID, "channel-1", "water the plants", clk.Now().Add(time.Hour), MemoOptions{GuildID: guildID})
+		return err
+	}
+	for n := 0; n < 2; n++ {
+		if err := create("user-1", "guild-1"); err != nil {
+			t.Fatalf("memo %d: CreateMemo() error = %v", n+1, err)
+		}
+	}
+
+	if err := create("user-1", "guild-1"); err == nil || !strings.Contains(err.Error(), "the limit is 2") {
+		t.Fatalf("CreateMemo() over the limit error = %v, want the limit", err)
+	}
+	// The limit is per user and per server
+	if err := create("user-2", "guild-1"); err != nil {
+		t.Fatalf("CreateMemo() for another user error = %v", err)
+	}
+	if err := create("user-1", "guild-2"); err != nil {
+		t.Fatalf("CreateMemo() in another server error = %v", err)
+	}
+
+	// Delivered memos no longer count
+	memos, err := svc.ListUserPendingMemos(ctx, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.MarkMemoAsSent(ctx, memos[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("user-1", "guild-1"); err != nil {
+		t.Fatalf("CreateMemo() after a delivery error = %v", err)
+	}
+}
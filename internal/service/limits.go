@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"memo-bot/internal/metrics"
+)
+
+// Limits caps how many pending memos can pile up, so one user can't queue
+// thousands of reminders for the scheduler to post. Memos that failed to be
+// delivered aren't counted, since they are never posted. Zero disables a limit.
+type Limits struct {
+	// MaxPendingPerUser caps a user's pending memos across all channels and servers
+	MaxPendingPerUser int
+	// MaxPendingPerChannel caps the pending memos stored in one channel, by anyone
+	MaxPendingPerChannel int
+}
+
+// SetLimits sets the limits checked when memos are created. Servers can
+// further limit their members with /config.
+func (s *MemoService) SetLimits(limits Limits) {
+	s.limits = limits
+}
+
+// fullQuota is a limit on a user's pending memos that has been reached
+type fullQuota struct {
+	count int64
+	max   int
+	// where is "" for the bot-wide limit and " in this server" for a server's
+	where string
+}
+
+// fullUserQuota returns the bot-wide or guildID's limit on userID's pending
+// memos if one is reached, or nil if they can own another memo
+func (s *MemoService) fullUserQuota(ctx context.Context, userID, guildID string) (*fullQuota, error) {
+	if max := s.limits.MaxPendingPerUser; max > 0 {
+		count, err := s.queries.CountUserPendingMemos(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count reminders: %v", err)
+		}
+		if count >= int64(max) {
+			return &fullQuota{count: count, max: max}, nil
+		}
+	}
+	return s.fullGuildQuota(ctx, userID, guildID)
+}
+
+// checkLimits enforces the bot-wide limits and the server's own limit before
+// userID creates a memo in channelID
+func (s *MemoService) checkLimits(ctx context.Context, userID, channelID, guildID string) error {
+	quota, err := s.fullUserQuota(ctx, userID, guildID)
+	if err != nil {
+		return err
+	}
+	if quota != nil {
+		return quotaExceeded("you already have %d pending memos%s, the limit is %d. Delete some with `/delete` first", quota.count, quota.where, quota.max)
+	}
+
+	if max := s.limits.MaxPendingPerChannel; max > 0 {
+		count, err := s.queries.CountPendingMemosInChannel(ctx, channelID)
+		if err != nil {
+			return fmt.Errorf("failed to count the channel's reminders: %v", err)
+		}
+		if count >= int64(max) {
+			return quotaExceeded("<#%s> already has %d pending memos, the limit is %d. Wait for some to be delivered or use another channel", channelID, count, max)
+		}
+	}
+	return nil
+}
+
+// quotaExceeded counts a memo refused for going over a limit and returns the reason
+func quotaExceeded(format string, args ...any) error {
+	metrics.MemosRejected.WithLabelValues(metrics.RejectedQuota).Inc()
+	return fmt.Errorf(format, args...)
+}
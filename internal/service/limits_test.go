@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"memo-bot/internal/recurrence"
+)
+
+func TestMaxPendingPerUserAcrossServers(t *testing.T) {
+	svc, _, clk := newTestService()
+	svc.SetLimits(Limits{MaxPendingPerUser: 2})
+	ctx := context.Background()
+
+	create := func(userID, channelID, guildID string) error {
+		_, err := svc.CreateMemo(ctx, userID, channelID, "water the plants", clk.Now().Add(time.Hour), MemoOptions{GuildID: guildID})
+		return err
+	}
+	if err := create("user-1", "channel-1", "guild-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("user-1", "channel-2", "guild-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The limit counts memos in every server, and direct messages
+	err := create("user-1", "dm-channel", "")
+	if err == nil || !strings.Contains(err.Error(), "you already have 2 pending memos, the limit is 2") {
+		t.Fatalf("CreateMemo() over the limit error = %v, want the limit", err)
+	}
+	if err := create("user-2", "channel-1", "guild-1"); err != nil {
+		t.Fatalf("CreateMemo() for another user error = %v", err)
+	}
+}
+
+func TestMaxPendingPerChannel(t *testing.T) {
+	svc, _, clk := newTestService()
+	svc.SetLimits(Limits{MaxPendingPerChannel: 2})
+	ctx := context.Background()
+
+	create := func(userID, channelID string) error {
+		_, err := svc.CreateMemo(ctx, userID, channelID, "water the plants", clk.Now().Add(time.Hour), MemoOptions{GuildID: "guild-1"})
+		return err
+	}
+	if err := create("user-1", "channel-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("user-2", "channel-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The limit counts everyone's memos in the channel
+	err := create("user-3", "channel-1")
+	if err == nil || !strings.Contains(err.Error(), "<#channel-1> already has 2 pending memos") {
+		t.Fatalf("CreateMemo() over the limit error = %v, want the limit", err)
+	}
+	if err := create("user-3", "channel-2"); err != nil {
+		t.Fatalf("CreateMemo() in another channel error = %v", err)
+	}
+}
+
+func TestFailedMemosDontCount(t *testing.T) {
+	svc, _, clk := newTestService()
+	svc.SetLimits(Limits{MaxPendingPerUser: 1, MaxPendingPerChannel: 1})
+	ctx := context.Background()
+	if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", clk.Now().Add(time.Minute), MemoOptions{GuildID: "guild-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The channel was deleted, so the memo can never be delivered
+	clk.Advance(time.Minute)
+	claimed, err := svc.ClaimDueReminders(ctx, "worker-1", clk.Now(), time.Minute, 10)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimDueReminders() = %d memos, %v", len(claimed), err)
+	}
+	if failed, err := svc.RecordDeliveryFailure(ctx, claimed[0], errors.New("unknown channel"), true, clk.Now()); err != nil || !failed {
+		t.Fatalf("RecordDeliveryFailure() = %v, %v; want the memo failed", failed, err)
+	}
+
+	if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", clk.Now().Add(time.Hour), MemoOptions{GuildID: "guild-1"}); err != nil {
+		t.Fatalf("CreateMemo() with only a failed memo pending error = %v, want it allowed", err)
+	}
+}
+
+func TestSnoozeIgnoresLimits(t *testing.T) {
+	svc, store, clk := newTestService()
+	ctx := context.Background()
+	daily, err := recurrence.Parse("daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := MemoOptions{GuildID: "guild-1", Recurrence: daily}
+	if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", clk.Now().Add(time.Hour), opts); err != nil {
+		t.Fatal(err)
+	}
+	svc.SetLimits(Limits{MaxPendingPerUser: 1, MaxPendingPerChannel: 1})
+
+	memos := store.Memos()
+	if err := svc.SnoozeMemo(ctx, memos[0].ID, "user-1", clk.Now().Add(2*time.Hour)); err != nil {
+		t.Fatalf("SnoozeMemo() of a recurring memo error = %v, want no limit", err)
+	}
+}
@@ -4,22 +4,111 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"memo-bot/internal/clock"
 	"memo-bot/internal/db"
+	"memo-bot/internal/metrics"
+	"memo-bot/internal/recurrence"
+	"memo-bot/internal/timeutil"
 )
 
+// Delivery targets for reminders
+const (
+	DeliveryChannel = "channel"
+	DeliveryDM      = "dm"
+	DeliveryBoth    = "both"
+)
+
+// MemoOptions holds the optional settings of a new memo
+type MemoOptions struct {
+	// Recurrence makes the memo repeat instead of being retired after delivery
+	Recurrence *recurrence.Rule
+	// Delivery is one of DeliveryChannel (default), DeliveryDM or DeliveryBoth
+	Delivery string
+	// GuildID is the server the memo was created in, empty for DMs
+	GuildID string
+	// SourceMessageID links the memo to the message it reminds about
+	SourceMessageID string
+	// Mentions are the users and roles pinged when the memo is delivered
+	Mentions []Mention
+
+	// followUp marks the snooze of a recurring memo, which isn't held to the
+	// server's limits since it replaces an occurrence the user already had
+	followUp bool
+}
+
+// nullString maps an empty string to NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// Delivery retry policy: transient failures back off exponentially from
+// retryBaseDelay up to retryMaxDelay; after maxDeliveryAttempts the memo is failed.
+const (
+	maxDeliveryAttempts = 8
+	retryBaseDelay      = 30 * time.Second
+	retryMaxDelay       = time.Hour
+)
+
+// ScheduleNotifier is told whenever a memo's reminder time is created, moved or
+// removed, so an in-memory scheduler can stay in sync with the database
+type ScheduleNotifier interface {
+	Schedule(memoID int32, remindAt time.Time)
+	Cancel(memoID int32)
+}
+
+// TxQuerier is implemented by storage backends that can run several queries in
+// one database transaction: fn's queries are committed if it returns nil and
+// rolled back otherwise
+type TxQuerier interface {
+	InTx(ctx context.Context, fn func(q db.Querier) error) error
+}
+
 type MemoService struct {
-	queries db.Querier
+	queries  db.Querier
+	clock    clock.Clock
+	notifier ScheduleNotifier
+	limits   Limits
 }
 
-func NewMemoService(dbConn *sql.DB) *MemoService {
+// NewMemoService creates a service on top of the queries of any storage backend.
+// clk decides what "in the past" means when memos are created or moved.
+func NewMemoService(queries db.Querier, clk clock.Clock) *MemoService {
 	return &MemoService{
-		queries: db.New(dbConn),
+		queries: queries,
+		clock:   clk,
 	}
 }
 
+// SetScheduleNotifier registers the scheduler to notify about schedule changes
+func (s *MemoService) SetScheduleNotifier(n ScheduleNotifier) {
+	s.notifier = n
+}
+
+func (s *MemoService) notifySchedule(memoID int32, remindAt time.Time) {
+	if s.notifier != nil {
+		s.notifier.Schedule(memoID, remindAt)
+	}
+}
+
+func (s *MemoService) notifyCancel(memoID int32) {
+	if s.notifier != nil {
+		s.notifier.Cancel(memoID)
+	}
+}
+
+// inTx runs fn in a transaction if the storage backend supports them, and
+// directly on the queries otherwise
+func (s *MemoService) inTx(ctx context.Context, fn func(q db.Querier) error) error {
+	if tx, ok := s.queries.(TxQuerier); ok {
+		return tx.InTx(ctx, fn)
+	}
+	return fn(s.queries)
+}
+
 func (s *MemoService) CreateUser(ctx context.Context, userID, username string) error {
 	_, err := s.queries.CreateUser(ctx, db.CreateUserParams{
 		UserID:   userID,
@@ -38,29 +127,119 @@ func (s *MemoService) UpdateUserDiscordChannel(ctx context.Context, userID, chan
 	})
 }
 
-func (s *MemoService) CreateMemo(ctx context.Context, discordUserID, discordChannelID, content string, remindAt time.Time) error {
+// SetUserTimezone stores the preferred IANA timezone for a user
+func (s *MemoService) SetUserTimezone(ctx context.Context, userID, username, timezone string) error {
+	if !timeutil.ValidTimezone(timezone) {
+		return fmt.Errorf("unknown timezone %q. Use an IANA name like Europe/Berlin", timezone)
+	}
+
+	err := s.queries.SetUserTimezone(ctx, db.SetUserTimezoneParams{
+		UserID:   userID,
+		Username: username,
+		Timezone: sql.NullString{
+			String: timezone,
+			Valid:  true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save timezone: %v", err)
+	}
+	return nil
+}
+
+// UserTimezone returns the user's preferred timezone, or an empty string if none is set
+func (s *MemoService) UserTimezone(ctx context.Context, userID string) (string, error) {
+	user, err := s.queries.GetUser(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.Timezone.String, nil
+}
+
+// userLocation resolves the user's timezone, or the server's, falling back to
+// fallback when neither is set or valid
+func (s *MemoService) userLocation(ctx context.Context, userID, guildID string, fallback *time.Location) *time.Location {
+	tz, err := s.Timezone(ctx, userID, guildID)
+	if err != nil || tz == "" {
+		return fallback
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
+
+// CreateMemo stores a new memo with the given options and returns it. A
+// recurring memo starts at the rule's first occurrence at or after remindAt,
+// in the owner's timezone or else remindAt's.
+func (s *MemoService) CreateMemo(ctx context.Context, discordUserID, discordChannelID, content string, remindAt time.Time, opts MemoOptions) (*db.Memo, error) {
 	// Check if reminder time is in the past
-	if remindAt.Before(time.Now()) {
-		return fmt.Errorf("reminder time must be in the future")
+	if remindAt.Before(s.clock.Now()) {
+		return nil, fmt.Errorf("reminder time must be in the future")
+	}
+
+	var rec sql.NullString
+	if opts.Recurrence != nil {
+		loc := s.userLocation(ctx, discordUserID, opts.GuildID, remindAt.Location())
+		first, ok := opts.Recurrence.First(remindAt, loc)
+		if !ok {
+			return nil, fmt.Errorf("the repeat rule ends before the reminder time")
+		}
+		remindAt = first
+		rec = sql.NullString{String: opts.Recurrence.String(), Valid: true}
+	}
+
+	delivery := opts.Delivery
+	switch delivery {
+	case "":
+		delivery = DeliveryChannel
+	case DeliveryChannel, DeliveryDM, DeliveryBoth:
+	default:
+		return nil, fmt.Errorf("invalid delivery target %q", delivery)
+	}
+
+	// Direct messages can't ping anyone else
+	if len(opts.Mentions) > 0 && delivery == DeliveryDM {
+		return nil, fmt.Errorf("memos that mention someone must be delivered in the channel")
+	}
+	mentions, err := FormatMentions(opts.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.followUp {
+		if err := s.checkLimits(ctx, discordUserID, discordChannelID, opts.GuildID); err != nil {
+			return nil, err
+		}
 	}
 
-	_, err := s.queries.CreateMemo(ctx, db.CreateMemoParams{
+	memo, err := s.queries.CreateMemo(ctx, db.CreateMemoParams{
 		DiscordUserID:    discordUserID,
 		DiscordChannelID: discordChannelID,
 		Content:          content,
 		RemindAt:         remindAt,
+		Recurrence:       rec,
+		Delivery:         delivery,
+		GuildID:          nullString(opts.GuildID),
+		SourceMessageID:  nullString(opts.SourceMessageID),
+		Mentions:         nullString(mentions),
 	})
 
 	if err != nil {
 		// Check for specific database errors and convert them to user-friendly messages
 		if strings.Contains(err.Error(), "remind_at_check") {
-			return fmt.Errorf("reminder time must be in the future")
+			return nil, fmt.Errorf("reminder time must be in the future")
 		}
 		// Add other specific error cases here if needed
-		return fmt.Errorf("failed to create reminder: %v", err)
+		return nil, fmt.Errorf("failed to create reminder: %v", err)
 	}
 
-	return nil
+	metrics.MemosCreated.Inc()
+	s.notifySchedule(memo.ID, memo.RemindAt)
+	return &memo, nil
 }
 
 func (s *MemoService) ListPendingMemos(ctx context.Context, discordUserID, discordChannelID string) ([]db.Memo, error) {
@@ -74,6 +253,31 @@ func (s *MemoService) ListPendingMemos(ctx context.Context, discordUserID, disco
 	return memos, nil
 }
 
+// ListUserPendingMemos returns the user's pending memos across all channels
+func (s *MemoService) ListUserPendingMemos(ctx context.Context, discordUserID string) ([]db.Memo, error) {
+	memos, err := s.queries.ListUserPendingMemos(ctx, discordUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch your reminders: %v", err)
+	}
+	return memos, nil
+}
+
+// likeEscaper escapes the LIKE wildcards, with the backslash the queries use as ESCAPE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchPendingMemos returns the user's pending memos whose content or ID starts with prefix
+func (s *MemoService) SearchPendingMemos(ctx context.Context, discordUserID, prefix string, limit int32) ([]db.Memo, error) {
+	memos, err := s.queries.SearchPendingMemos(ctx, db.SearchPendingMemosParams{
+		DiscordUserID: discordUserID,
+		Prefix:        sql.NullString{String: likeEscaper.Replace(prefix), Valid: true},
+		MaxResults:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search your reminders: %v", err)
+	}
+	return memos, nil
+}
+
 func (s *MemoService) GetReminderCounts(ctx context.Context, discordUserID string) ([]db.GetReminderCountsRow, error) {
 	counts, err := s.queries.GetReminderCounts(ctx, discordUserID)
 	if err != nil {
@@ -83,27 +287,292 @@ func (s *MemoService) GetReminderCounts(ctx context.Context, discordUserID strin
 }
 
 func (s *MemoService) DeleteMemo(ctx context.Context, memoID int32, discordUserID string) error {
-	err := s.queries.DeleteMemo(ctx, db.DeleteMemoParams{
+	if err := deleteMemo(ctx, s.queries, memoID, discordUserID); err != nil {
+		return err
+	}
+	metrics.MemosDeleted.Inc()
+	s.notifyCancel(memoID)
+	return nil
+}
+
+// deleteMemo deletes discordUserID's memo with q, failing if there is none
+func deleteMemo(ctx context.Context, q db.Querier, memoID int32, discordUserID string) error {
+	n, err := q.DeleteMemo(ctx, db.DeleteMemoParams{
 		ID:            memoID,
 		DiscordUserID: discordUserID,
 	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("reminder #%d not found or you don't have permission to delete it", memoID)
-		}
 		return fmt.Errorf("failed to delete reminder: %v", err)
 	}
+	if n == 0 {
+		return fmt.Errorf("reminder #%d not found or you don't have permission to delete it", memoID)
+	}
 	return nil
 }
 
+// UpdateMemo changes the content and/or reminder time of a pending memo owned by
+// discordUserID. A nil content or remindAt keeps the current value. A worker
+// delivering the memo loses its claim, so it can't mark the edited memo as sent.
+func (s *MemoService) UpdateMemo(ctx context.Context, memoID int32, discordUserID string, content *string, remindAt *time.Time) (*db.Memo, error) {
+	memo, err := s.GetMemo(ctx, memoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if memo.DiscordUserID != discordUserID {
+		return nil, fmt.Errorf("memo #%d belongs to <@%s>. You can only edit your own memos", memoID, memo.DiscordUserID)
+	}
+	if memo.Sent.Valid && memo.Sent.Bool {
+		return nil, fmt.Errorf("memo #%d has already been delivered", memoID)
+	}
+
+	params := db.UpdateMemoParams{
+		ID:            memoID,
+		DiscordUserID: discordUserID,
+		Content:       memo.Content,
+		RemindAt:      memo.RemindAt,
+	}
+	if content != nil {
+		params.Content = *content
+	}
+	if remindAt != nil {
+		if remindAt.Before(s.clock.Now()) {
+			return nil, fmt.Errorf("reminder time must be in the future")
+		}
+		params.RemindAt = *remindAt
+		// Like a new series, a moved one starts on a day matching its rule
+		if memo.Recurrence.Valid {
+			if rule, err := recurrence.Parse(memo.Recurrence.String); err == nil {
+				loc := s.userLocation(ctx, discordUserID, memo.GuildID.String, remindAt.Location())
+				first, ok := rule.First(*remindAt, loc)
+				if !ok {
+					return nil, fmt.Errorf("the repeat rule ends before the reminder time")
+				}
+				params.RemindAt = first
+			}
+		}
+	}
+
+	updated, err := s.queries.UpdateMemo(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reminder #%d not found or already delivered", memoID)
+		}
+		return nil, fmt.Errorf("failed to update reminder: %v", err)
+	}
+	s.notifySchedule(updated.ID, updated.RemindAt)
+	return &updated, nil
+}
+
 func (s *MemoService) GetPendingReminders(ctx context.Context, now time.Time) ([]db.Memo, error) {
 	return s.queries.GetPendingReminders(ctx, now)
 }
 
+// ClaimDueReminders leases up to batchSize due memos to workerID until now+lease.
+// Claimed memos are invisible to other workers until they are completed, a failed
+// delivery is recorded, or the lease expires (e.g. because the worker crashed
+// mid-delivery).
+func (s *MemoService) ClaimDueReminders(ctx context.Context, workerID string, now time.Time, lease time.Duration, batchSize int32) ([]db.Memo, error) {
+	memos, err := s.queries.ClaimPendingReminders(ctx, db.ClaimPendingRemindersParams{
+		WorkerID:   sql.NullString{String: workerID, Valid: true},
+		LeaseUntil: sql.NullTime{Time: now.Add(lease), Valid: true},
+		Now:        now,
+		BatchSize:  batchSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim reminders: %w", err)
+	}
+
+	// UPDATE ... RETURNING doesn't preserve the subquery's order
+	sort.Slice(memos, func(i, j int) bool {
+		return memos[i].RemindAt.Before(memos[j].RemindAt)
+	})
+	return memos, nil
+}
+
+// RenewClaim extends the worker's lease on a claimed memo until now+lease, right
+// before it is sent. It reports false if the claim was lost, e.g. because the
+// batch outlived its lease and another worker reclaimed the memo.
+func (s *MemoService) RenewClaim(ctx context.Context, memo db.Memo, now time.Time, lease time.Duration) (bool, error) {
+	n, err := s.queries.RenewClaim(ctx, db.RenewClaimParams{
+		ID:           memo.ID,
+		ClaimedBy:    memo.ClaimedBy,
+		ClaimedUntil: sql.NullTime{Time: now.Add(lease), Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to renew claim on reminder #%d: %w", memo.ID, err)
+	}
+	return n > 0, nil
+}
+
+// RecordDeliveryFailure stores a failed delivery attempt and releases the claim.
+// Transient errors are retried with exponential backoff; permanent errors, or too
+// many attempts, put the memo in the failed state where only its owner sees it in
+// /list. It reports whether the memo is now failed.
+func (s *MemoService) RecordDeliveryFailure(ctx context.Context, memo db.Memo, deliveryErr error, permanent bool, now time.Time) (bool, error) {
+	attempts := memo.Attempts + 1
+	failed := permanent || attempts >= maxDeliveryAttempts
+
+	var nextAttempt sql.NullTime
+	if !failed {
+		nextAttempt = sql.NullTime{Time: now.Add(retryDelay(attempts)), Valid: true}
+	}
+
+	n, err := s.queries.RecordDeliveryFailure(ctx, db.RecordDeliveryFailureParams{
+		ID:            memo.ID,
+		LastError:     sql.NullString{String: deliveryErr.Error(), Valid: true},
+		NextAttemptAt: nextAttempt,
+		Failed:        failed,
+		ClaimedBy:     memo.ClaimedBy,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record delivery failure: %w", err)
+	}
+	if n == 0 {
+		return false, claimLost(memo)
+	}
+
+	if failed {
+		s.notifyCancel(memo.ID)
+	}
+	return failed, nil
+}
+
+// retryDelay returns the backoff before the next attempt after attempts failures
+func retryDelay(attempts int32) time.Duration {
+	delay := retryBaseDelay
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
 func (s *MemoService) MarkMemoAsSent(ctx context.Context, memoID int32) error {
 	return s.queries.MarkMemoAsSent(ctx, memoID)
 }
 
+// CompleteReminder is called after a claimed memo has been delivered. Recurring
+// memos are moved to their next occurrence after now (evaluated in the owner's
+// timezone, or fallback, so wall-clock times survive DST changes); one-off memos
+// and finished series are marked as sent. Both only happen while the worker
+// still holds the claim; otherwise it returns an error.
+func (s *MemoService) CompleteReminder(ctx context.Context, memo db.Memo, now time.Time, fallback *time.Location) error {
+	if memo.Recurrence.Valid {
+		loc := s.userLocation(ctx, memo.DiscordUserID, memo.GuildID.String, fallback)
+		rule, err := recurrence.Parse(memo.Recurrence.String)
+		if err != nil {
+			// Retire the memo rather than re-sending it on every scan
+			if markErr := s.markClaimedMemoAsSent(ctx, memo); markErr != nil {
+				return markErr
+			}
+			return fmt.Errorf("invalid recurrence on memo #%d, marked as sent: %w", memo.ID, err)
+		}
+		if next, ok := rule.Next(memo.RemindAt, now, loc); ok {
+			n, err := s.queries.RescheduleMemo(ctx, db.RescheduleMemoParams{
+				ID:        memo.ID,
+				RemindAt:  next,
+				ClaimedBy: memo.ClaimedBy,
+			})
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return claimLost(memo)
+			}
+			s.notifySchedule(memo.ID, next)
+			return nil
+		}
+	}
+	if err := s.markClaimedMemoAsSent(ctx, memo); err != nil {
+		return err
+	}
+	s.notifyCancel(memo.ID)
+	return nil
+}
+
+func (s *MemoService) markClaimedMemoAsSent(ctx context.Context, memo db.Memo) error {
+	n, err := s.queries.MarkClaimedMemoAsSent(ctx, db.MarkClaimedMemoAsSentParams{
+		ID:        memo.ID,
+		ClaimedBy: memo.ClaimedBy,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return claimLost(memo)
+	}
+	return nil
+}
+
+// claimLost reports that a worker's claim on memo was taken over by another
+// worker after it expired, or dropped because the owner edited or snoozed the memo
+func claimLost(memo db.Memo) error {
+	return fmt.Errorf("claim of %s on memo #%d was lost, the memo was changed or another worker may have delivered it too", memo.ClaimedBy.String, memo.ID)
+}
+
+// SnoozeMemo pushes a delivered memo back to until. One-off memos are rescheduled
+// in place, dropping any worker's claim like UpdateMemo; recurring memos get a
+// one-off follow-up so the series keeps its schedule.
+func (s *MemoService) SnoozeMemo(ctx context.Context, memoID int32, discordUserID string, until time.Time) error {
+	if until.Before(s.clock.Now()) {
+		return fmt.Errorf("reminder time must be in the future")
+	}
+
+	memo, err := s.GetMemo(ctx, memoID)
+	if err != nil {
+		return err
+	}
+	if memo.DiscordUserID != discordUserID {
+		return fmt.Errorf("memo #%d belongs to <@%s>. You can only snooze your own memos", memoID, memo.DiscordUserID)
+	}
+
+	if memo.Recurrence.Valid {
+		_, err := s.CreateMemo(ctx, memo.DiscordUserID, memo.DiscordChannelID, memo.Content, until, MemoOptions{
+			Delivery:        memo.Delivery,
+			GuildID:         memo.GuildID.String,
+			SourceMessageID: memo.SourceMessageID.String,
+			Mentions:        ParseMentions(memo.Mentions.String),
+			followUp:        true,
+		})
+		return err
+	}
+
+	err = s.queries.SnoozeMemo(ctx, db.SnoozeMemoParams{
+		ID:       memoID,
+		RemindAt: until,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to snooze reminder: %v", err)
+	}
+	s.notifySchedule(memoID, until)
+	return nil
+}
+
+// AcknowledgeMemo marks a delivered memo as done by its owner
+func (s *MemoService) AcknowledgeMemo(ctx context.Context, memoID int32, discordUserID string) error {
+	memo, err := s.GetMemo(ctx, memoID)
+	if err != nil {
+		return err
+	}
+	if memo.DiscordUserID != discordUserID {
+		return fmt.Errorf("memo #%d belongs to <@%s>. Only the owner can mark it as done", memoID, memo.DiscordUserID)
+	}
+
+	// Recurring memos were already moved to their next occurrence on delivery
+	if memo.Recurrence.Valid {
+		return nil
+	}
+	return s.queries.MarkMemoAsSent(ctx, memoID)
+}
+
+// UpcomingReminders returns up to limit pending memos ordered by reminder time
+func (s *MemoService) UpcomingReminders(ctx context.Context, limit int32) ([]db.Memo, error) {
+	return s.queries.ListUpcomingReminders(ctx, limit)
+}
+
 // ListAllPendingMemosInChannel returns all pending memos in a specific channel
 func (s *MemoService) ListAllPendingMemosInChannel(ctx context.Context, discordChannelID string) ([]db.Memo, error) {
 	memos, err := s.queries.ListAllPendingMemosInChannel(ctx, discordChannelID)
@@ -0,0 +1,512 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"memo-bot/internal/clock"
+	"memo-bot/internal/db/memdb"
+	"memo-bot/internal/recurrence"
+)
+
+// testStart is a fixed "now" for tests, so results don't depend on the wall clock
+var testStart = time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+func newTestService() (*MemoService, *memdb.Querier, *clock.Fake) {
+	clk := clock.NewFake(testStart)
+	store := memdb.New(clk)
+	return NewMemoService(store, clk), store, clk
+}
+
+func TestCreateMemo(t *testing.T) {
+	weekly, err := recurrence.Parse("weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		remindAt     time.Duration
+		opts         MemoOptions
+		wantErr      string
+		wantDelivery string
+		wantRepeat   string
+	}{
+		{
+			name:         "defaults to channel delivery",
+			remindAt:     time.Hour,
+			wantDelivery: DeliveryChannel,
+		},
+		{
+			name:         "direct message delivery",
+			remindAt:     time.Hour,
+			opts:         MemoOptions{Delivery: DeliveryDM},
+			wantDelivery: DeliveryDM,
+		},
+		{
+			name:         "stores the recurrence rule",
+			remindAt:     time.Hour,
+			opts:         MemoOptions{Recurrence: weekly},
+			wantDelivery: DeliveryChannel,
+			wantRepeat:   "FREQ=WEEKLY",
+		},
+		{
+			name:     "rejects unknown delivery target",
+			remindAt: time.Hour,
+			opts:     MemoOptions{Delivery: "carrier-pigeon"},
+			wantErr:  "invalid delivery target",
+		},
+		{
+			name:     "rejects past time",
+			remindAt: -time.Minute,
+			wantErr:  "reminder time must be in the future",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store, clk := newTestService()
+
+			_, err := svc.CreateMemo(context.Background(), "user-1", "channel-1", "water the plants", clk.Now().Add(tt.remindAt), tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CreateMemo() error = %v, want %q", err, tt.wantErr)
+				}
+				if n := len(store.Memos()); n != 0 {
+					t.Fatalf("stored %d memos, want 0", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateMemo() error = %v", err)
+			}
+
+			memos := store.Memos()
+			if len(memos) != 1 {
+				t.Fatalf("stored %d memos, want 1", len(memos))
+			}
+			got := memos[0]
+			if got.Content != "water the plants" || got.DiscordUserID != "user-1" || got.DiscordChannelID != "channel-1" {
+				t.Errorf("stored memo = %+v", got)
+			}
+			if got.Delivery != tt.wantDelivery {
+				t.Errorf("Delivery = %q, want %q", got.Delivery, tt.wantDelivery)
+			}
+			if got.Recurrence.String != tt.wantRepeat {
+				t.Errorf("Recurrence = %q, want %q", got.Recurrence.String, tt.wantRepeat)
+			}
+		})
+	}
+}
+
+func TestDeleteMemo(t *testing.T) {
+	tests := []struct {
+		name      string
+		memoID    int32
+		userID    string
+		wantErr   string
+		wantMemos int
+	}{
+		{name: "owner deletes", memoID: 1, userID: "owner", wantMemos: 1},
+		{name: "other user can't delete", memoID: 1, userID: "intruder", wantErr: "reminder #1 not found or you don't have permission", wantMemos: 2},
+		{name: "unknown memo", memoID: 42, userID: "owner", wantErr: "reminder #42 not found", wantMemos: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store, clk := newTestService()
+			ctx := context.Background()
+			for _, content := range []string{"first", "second"} {
+				if _, err := svc.CreateMemo(ctx, "owner", "channel-1", content, clk.Now().Add(time.Hour), MemoOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := svc.DeleteMemo(ctx, tt.memoID, tt.userID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DeleteMemo() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("DeleteMemo() error = %v", err)
+			}
+			if n := len(store.Memos()); n != tt.wantMemos {
+				t.Fatalf("%d memos left, want %d", n, tt.wantMemos)
+			}
+		})
+	}
+}
+
+func TestGetPendingReminders(t *testing.T) {
+	svc, _, clk := newTestService()
+	ctx := context.Background()
+
+	base := clk.Now()
+	for _, memo := range []struct {
+		content string
+		at      time.Duration
+	}{
+		{"later", 3 * time.Hour},
+		{"second", 2 * time.Hour},
+		{"first", time.Hour},
+		{"delivered", 90 * time.Minute},
+	} {
+		if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", memo.content, base.Add(memo.at), MemoOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.MarkMemoAsSent(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Duration
+		want []string
+	}{
+		{name: "nothing due yet", now: 0, want: nil},
+		{name: "due exactly at remind_at", now: time.Hour, want: []string{"first"}},
+		{name: "ordered by remind_at, sent excluded", now: 2*time.Hour + time.Minute, want: []string{"first", "second"}},
+		{name: "everything due", now: 4 * time.Hour, want: []string{"first", "second", "later"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memos, err := svc.GetPendingReminders(ctx, base.Add(tt.now))
+			if err != nil {
+				t.Fatalf("GetPendingReminders() error = %v", err)
+			}
+			var got []string
+			for _, m := range memos {
+				got = append(got, m.Content)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("GetPendingReminders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPastTimeValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		call    func(svc *MemoService, at time.Time) error
+		offset  time.Duration
+		wantErr bool
+	}{
+		{
+			name: "create in the past",
+			call: func(svc *MemoService, at time.Time) error {
+				_, err := svc.CreateMemo(context.Background(), "user-1", "channel-1", "late", at, MemoOptions{})
+				return err
+			},
+			offset:  -time.Second,
+			wantErr: true,
+		},
+		{
+			name: "create in the future",
+			call: func(svc *MemoService, at time.Time) error {
+				_, err := svc.CreateMemo(context.Background(), "user-1", "channel-1", "soon", at, MemoOptions{})
+				return err
+			},
+			offset: time.Minute,
+		},
+		{
+			name: "edit to the past",
+			call: func(svc *MemoService, at time.Time) error {
+				_, err := svc.UpdateMemo(context.Background(), 1, "user-1", nil, &at)
+				return err
+			},
+			offset:  -time.Hour,
+			wantErr: true,
+		},
+		{
+			name: "snooze to the past",
+			call: func(svc *MemoService, at time.Time) error {
+				return svc.SnoozeMemo(context.Background(), 1, "user-1", at)
+			},
+			offset:  -time.Minute,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, clk := newTestService()
+			if _, err := svc.CreateMemo(context.Background(), "user-1", "channel-1", "existing", clk.Now().Add(time.Hour), MemoOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			err := tt.call(svc, clk.Now().Add(tt.offset))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "reminder time must be in the future") {
+					t.Fatalf("error = %v, want past-time error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRemindersComeDue(t *testing.T) {
+	svc, _, clk := newTestService()
+	ctx := context.Background()
+	if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", clk.Now().Add(15*time.Minute), MemoOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{advance: 14 * time.Minute, want: 0},
+		{advance: time.Minute, want: 1},
+		// Still leased to the first claim
+		{advance: time.Minute, want: 0},
+		// The lease expired without the memo being completed
+		{advance: 2 * time.Minute, want: 1},
+	}
+
+	for i, step := range steps {
+		clk.Advance(step.advance)
+		memos, err := svc.ClaimDueReminders(ctx, "worker-1", clk.Now(), 2*time.Minute, 10)
+		if err != nil {
+			t.Fatalf("step %d: ClaimDueReminders() error = %v", i, err)
+		}
+		if len(memos) != step.want {
+			t.Fatalf("step %d at %s: claimed %d reminders, want %d", i, clk.Now().Format(time.Kitchen), len(memos), step.want)
+		}
+	}
+}
+
+func TestLostClaim(t *testing.T) {
+	svc, store, clk := newTestService()
+	ctx := context.Background()
+	if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", clk.Now().Add(time.Minute), MemoOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	clk.Advance(time.Minute)
+
+	slow, err := svc.ClaimDueReminders(ctx, "worker-1", clk.Now(), 2*time.Minute, 10)
+	if err != nil || len(slow) != 1 {
+		t.Fatalf("ClaimDueReminders(worker-1) = %d memos, %v", len(slow), err)
+	}
+	if claimed, err := svc.RenewClaim(ctx, slow[0], clk.Now(), 2*time.Minute); err != nil || !claimed {
+		t.Fatalf("RenewClaim() within the lease = %v, %v, want true", claimed, err)
+	}
+
+	// worker-1 takes too long and worker-2 reclaims the memo
+	clk.Advance(3 * time.Minute)
+	fast, err := svc.ClaimDueReminders(ctx, "worker-2", clk.Now(), 2*time.Minute, 10)
+	if err != nil || len(fast) != 1 {
+		t.Fatalf("ClaimDueReminders(worker-2) = %d memos, %v", len(fast), err)
+	}
+
+	if claimed, err := svc.RenewClaim(ctx, slow[0], clk.Now(), 2*time.Minute); err != nil || claimed {
+		t.Fatalf("RenewClaim() after losing the claim = %v, %v, want false", claimed, err)
+	}
+	if err := svc.CompleteReminder(ctx, slow[0], clk.Now(), time.UTC); err == nil || !strings.Contains(err.Error(), "was lost") {
+		t.Fatalf("CompleteReminder() after losing the claim error = %v, want a lost claim", err)
+	}
+	if m := store.Memos()[0]; m.Sent.Bool || m.ClaimedBy.String != "worker-2" {
+		t.Fatalf("memo sent = %v, claimed by %q; want it pending and claimed by worker-2", m.Sent.Bool, m.ClaimedBy.String)
+	}
+
+	if err := svc.CompleteReminder(ctx, fast[0], clk.Now(), time.UTC); err != nil {
+		t.Fatalf("CompleteReminder() by the claim holder error = %v", err)
+	}
+}
+
+func TestChangeDuringDelivery(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(svc *MemoService, now time.Time) error
+	}{
+		{
+			name: "edit",
+			change: func(svc *MemoService, now time.Time) error {
+				content, remindAt := "retro", now.Add(time.Hour)
+				_, err := svc.UpdateMemo(context.Background(), 1, "user-1", &content, &remindAt)
+				return err
+			},
+		},
+		{
+			name: "snooze",
+			change: func(svc *MemoService, now time.Time) error {
+				return svc.SnoozeMemo(context.Background(), 1, "user-1", now.Add(time.Hour))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store, clk := newTestService()
+			ctx := context.Background()
+			if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", clk.Now().Add(time.Minute), MemoOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			clk.Advance(time.Minute)
+			claimed, err := svc.ClaimDueReminders(ctx, "worker-1", clk.Now(), 2*time.Minute, 10)
+			if err != nil || len(claimed) != 1 {
+				t.Fatalf("ClaimDueReminders() = %d memos, %v", len(claimed), err)
+			}
+
+			// The owner changes the memo while the worker is sending it
+			if err := tt.change(svc, clk.Now()); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := svc.CompleteReminder(ctx, claimed[0], clk.Now(), time.UTC); err == nil || !strings.Contains(err.Error(), "was lost") {
+				t.Fatalf("CompleteReminder() after the change error = %v, want a lost claim", err)
+			}
+			if _, err := svc.RecordDeliveryFailure(ctx, claimed[0], errors.New("timeout"), false, clk.Now()); err == nil || !strings.Contains(err.Error(), "was lost") {
+				t.Fatalf("RecordDeliveryFailure() after the change error = %v, want a lost claim", err)
+			}
+			m := store.Memos()[0]
+			if m.Sent.Bool || m.Attempts != 0 || !m.RemindAt.Equal(clk.Now().Add(time.Hour)) {
+				t.Fatalf("memo sent = %v with %d attempts at %s, want the change kept", m.Sent.Bool, m.Attempts, m.RemindAt)
+			}
+		})
+	}
+}
+
+func TestRecurringMemoStartsOnMatchingDay(t *testing.T) {
+	svc, _, clk := newTestService()
+	ctx := context.Background()
+	weekdays, err := recurrence.Parse("weekdays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saturday := time.Date(2026, time.March, 7, 9, 0, 0, 0, time.UTC)
+	monday := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+
+	memo, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", saturday, MemoOptions{Recurrence: weekdays})
+	if err != nil {
+		t.Fatalf("CreateMemo() error = %v", err)
+	}
+	if !memo.RemindAt.Equal(monday) {
+		t.Fatalf("first reminder at %s, want Monday %s", memo.RemindAt, monday)
+	}
+
+	// Moving the series realigns it too
+	sunday := saturday.AddDate(0, 0, 1)
+	updated, err := svc.UpdateMemo(ctx, memo.ID, "user-1", nil, &sunday)
+	if err != nil {
+		t.Fatalf("UpdateMemo() error = %v", err)
+	}
+	if !updated.RemindAt.Equal(monday) {
+		t.Fatalf("moved reminder at %s, want Monday %s", updated.RemindAt, monday)
+	}
+
+	// A one-off memo is kept where it was asked for
+	memo, err = svc.CreateMemo(ctx, "user-1", "channel-1", "laundry", saturday, MemoOptions{})
+	if err != nil || !memo.RemindAt.Equal(saturday) {
+		t.Fatalf("one-off memo at %v, %v; want %s", memo, err, saturday)
+	}
+
+	ended, err := recurrence.Parse("FREQ=WEEKLY;BYDAY=MO;UNTIL=20260308")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", clk.Now().AddDate(0, 0, 5), MemoOptions{Recurrence: ended}); err == nil || !strings.Contains(err.Error(), "repeat rule ends") {
+		t.Fatalf("CreateMemo() past UNTIL error = %v, want the rule to have ended", err)
+	}
+}
+
+func TestSnoozeRecurringMemoKeepsTargets(t *testing.T) {
+	svc, store, clk := newTestService()
+	ctx := context.Background()
+	daily, err := recurrence.Parse("daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := MemoOptions{
+		Recurrence: daily,
+		Delivery:   DeliveryBoth,
+		GuildID:    "guild-1",
+		Mentions:   []Mention{{Kind: MentionRole, ID: "oncall"}, {Kind: MentionUser, ID: "user-2"}},
+	}
+	if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "check the pager", clk.Now().Add(time.Hour), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	until := clk.Now().Add(2 * time.Hour)
+	if err := svc.SnoozeMemo(ctx, 1, "user-1", until); err != nil {
+		t.Fatalf("SnoozeMemo() error = %v", err)
+	}
+
+	memos := store.Memos()
+	if len(memos) != 2 {
+		t.Fatalf("%d memos after snoozing a recurring memo, want the series and a one-off", len(memos))
+	}
+	series, snoozed := memos[0], memos[1]
+	if snoozed.Recurrence.Valid || !snoozed.RemindAt.Equal(until) {
+		t.Fatalf("snoozed occurrence repeats = %v at %s, want a one-off at %s", snoozed.Recurrence.Valid, snoozed.RemindAt, until)
+	}
+	if snoozed.Mentions != series.Mentions || snoozed.Delivery != series.Delivery || snoozed.GuildID != series.GuildID {
+		t.Fatalf("snoozed occurrence mentions %q via %s in %q, want %q via %s in %q",
+			snoozed.Mentions.String, snoozed.Delivery, snoozed.GuildID.String,
+			series.Mentions.String, series.Delivery, series.GuildID.String)
+	}
+}
+
+func TestCompleteReminderAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		wantNext time.Time
+	}{
+		{
+			name:     "spring forward keeps 09:00 local",
+			start:    time.Date(2026, time.March, 7, 9, 0, 0, 0, newYork),
+			wantNext: time.Date(2026, time.March, 8, 9, 0, 0, 0, newYork),
+		},
+		{
+			name:     "fall back keeps 09:00 local",
+			start:    time.Date(2026, time.October, 31, 9, 0, 0, 0, newYork),
+			wantNext: time.Date(2026, time.November, 1, 9, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(tt.start.Add(-time.Hour))
+			store := memdb.New(clk)
+			svc := NewMemoService(store, clk)
+			ctx := context.Background()
+
+			daily, _ := recurrence.Parse("daily")
+			if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "vitamins", tt.start, MemoOptions{Recurrence: daily}); err != nil {
+				t.Fatal(err)
+			}
+
+			clk.Set(tt.start)
+			memos, err := svc.ClaimDueReminders(ctx, "worker-1", clk.Now(), time.Minute, 10)
+			if err != nil || len(memos) != 1 {
+				t.Fatalf("ClaimDueReminders() = %d memos, %v", len(memos), err)
+			}
+			if err := svc.CompleteReminder(ctx, memos[0], clk.Now(), newYork); err != nil {
+				t.Fatalf("CompleteReminder() error = %v", err)
+			}
+
+			got := store.Memos()[0].RemindAt
+			if !got.Equal(tt.wantNext) {
+				t.Fatalf("next occurrence = %s, want %s", got.In(newYork), tt.wantNext)
+			}
+			if elapsed := got.Sub(tt.start); elapsed == 24*time.Hour {
+				t.Fatalf("next occurrence is exactly 24h later, DST shift was ignored")
+			}
+		})
+	}
+}
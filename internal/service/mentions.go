@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of mention targets
+const (
+	MentionUser = "user"
+	MentionRole = "role"
+)
+
+// Mention is a user or role pinged when a memo is delivered
+type Mention struct {
+	Kind string
+	ID   string
+}
+
+// String returns the Discord mention markup, e.g. <@123> or <@&456>
+func (m Mention) String() string {
+	if m.Kind == MentionRole {
+		return fmt.Sprintf("<@&%s>", m.ID)
+	}
+	return fmt.Sprintf("<@%s>", m.ID)
+}
+
+// FormatMentions encodes mentions as "user:123,role:456", the format of the
+// memos.mentions column. Duplicates are dropped.
+func FormatMentions(mentions []Mention) (string, error) {
+	parts := make([]string, 0, len(mentions))
+	seen := make(map[Mention]bool, len(mentions))
+	for _, m := range mentions {
+		if m.Kind != MentionUser && m.Kind != MentionRole {
+			return "", fmt.Errorf("invalid mention target %q", m.Kind)
+		}
+		if m.ID == "" || strings.ContainsAny(m.ID, ":,") {
+			return "", fmt.Errorf("invalid %s ID %q", m.Kind, m.ID)
+		}
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		parts = append(parts, m.Kind+":"+m.ID)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// ParseMentions decodes the memos.mentions column. Malformed entries are skipped.
+func ParseMentions(s string) []Mention {
+	var mentions []Mention
+	for _, part := range strings.Split(s, ",") {
+		kind, id, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || id == "" || (kind != MentionUser && kind != MentionRole) {
+			continue
+		}
+		mentions = append(mentions, Mention{Kind: kind, ID: id})
+	}
+	return mentions
+}
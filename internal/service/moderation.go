@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"memo-bot/internal/db"
+	"memo-bot/internal/metrics"
+)
+
+// Moderation actions recorded in the memo_moderation_log table
+const (
+	ModerationDelete   = "delete"
+	ModerationReassign = "reassign"
+	ModerationPurge    = "purge"
+)
+
+// DeleteMemoAsModerator deletes someone else's memo on behalf of moderatorID
+// and records who did it, both or neither. The caller checks that moderatorID
+// may do so.
+func (s *MemoService) DeleteMemoAsModerator(ctx context.Context, memo db.Memo, moderatorID string) error {
+	err := s.inTx(ctx, func(q db.Querier) error {
+		if err := deleteMemo(ctx, q, memo.ID, memo.DiscordUserID); err != nil {
+			return err
+		}
+		return recordModeration(ctx, q, memo, ModerationDelete, moderatorID, "")
+	})
+	if err != nil {
+		return err
+	}
+	metrics.MemosDeleted.Inc()
+	s.notifyCancel(memo.ID)
+	return nil
+}
+
+// ReassignMemo hands a pending memo over to newOwnerID on behalf of
+// moderatorID and records who did it. The caller checks that moderatorID may do so.
+func (s *MemoService) ReassignMemo(ctx context.Context, memoID int32, newOwnerID, moderatorID string) (*db.Memo, error) {
+	memo, err := s.GetMemo(ctx, memoID)
+	if err != nil {
+		return nil, err
+	}
+	if memo.DiscordUserID == newOwnerID {
+		return nil, fmt.Errorf("memo #%d already belongs to <@%s>", memoID, newOwnerID)
+	}
+
+	// Moderators can't push a member past their pending memo limits
+	quota, err := s.fullUserQuota(ctx, newOwnerID, memo.GuildID.String)
+	if err != nil {
+		return nil, err
+	}
+	if quota != nil {
+		return nil, fmt.Errorf("<@%s> already has %d pending memos%s, the limit is %d", newOwnerID, quota.count, quota.where, quota.max)
+	}
+
+	var updated db.Memo
+	err = s.inTx(ctx, func(q db.Querier) error {
+		updated, err = q.ReassignMemo(ctx, db.ReassignMemoParams{
+			ID:            memoID,
+			DiscordUserID: newOwnerID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("reminder #%d not found or already delivered", memoID)
+			}
+			return fmt.Errorf("failed to reassign reminder: %v", err)
+		}
+		return recordModeration(ctx, q, *memo, ModerationReassign, moderatorID, newOwnerID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// PurgeUserMemos deletes every pending memo userID has in guildID, e.g. after
+// they left the server, and records moderatorID as the one who did it. Memos
+// created before their server was recorded have no guild ID; those in
+// legacyChannelIDs, which the caller resolved to guildID, are deleted too. It
+// returns the number of memos deleted.
+func (s *MemoService) PurgeUserMemos(ctx context.Context, guildID, userID, moderatorID string, legacyChannelIDs []string) (int, error) {
+	if guildID == "" {
+		return 0, fmt.Errorf("memos can only be purged in a server")
+	}
+
+	var deleted []db.Memo
+	err := s.inTx(ctx, func(q db.Querier) error {
+		var err error
+		deleted, err = q.DeletePendingMemosByUserInGuild(ctx, db.DeletePendingMemosByUserInGuildParams{
+			DiscordUserID: userID,
+			GuildID:       nullString(guildID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to purge reminders: %v", err)
+		}
+		for _, channelID := range legacyChannelIDs {
+			legacy, err := q.DeletePendingLegacyMemosByUserInChannel(ctx, db.DeletePendingLegacyMemosByUserInChannelParams{
+				DiscordUserID:    userID,
+				DiscordChannelID: channelID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to purge reminders: %v", err)
+			}
+			for _, memo := range legacy {
+				// Log them under the server they were resolved to
+				memo.GuildID = nullString(guildID)
+				deleted = append(deleted, memo)
+			}
+		}
+		for _, memo := range deleted {
+			if err := recordModeration(ctx, q, memo, ModerationPurge, moderatorID, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, memo := range deleted {
+		metrics.MemosDeleted.Inc()
+		s.notifyCancel(memo.ID)
+	}
+	return len(deleted), nil
+}
+
+// recordModeration writes an entry to the moderation log with q
+func recordModeration(ctx context.Context, q db.Querier, memo db.Memo, action, moderatorID, newOwnerID string) error {
+	err := q.RecordModeration(ctx, db.RecordModerationParams{
+		MemoID:      memo.ID,
+		GuildID:     memo.GuildID,
+		Action:      action,
+		ModeratorID: moderatorID,
+		OwnerID:     memo.DiscordUserID,
+		NewOwnerID:  nullString(newOwnerID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record moderation of reminder #%d: %w", memo.ID, err)
+	}
+	return nil
+}
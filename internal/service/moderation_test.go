@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"memo-bot/internal/db"
+)
+
+func TestReassignMemo(t *testing.T) {
+	svc, store, clk := newTestService()
+	ctx := context.Background()
+	for n := 0; n < 2; n++ {
+		if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "water the plants", clk.Now().Add(time.Hour), MemoOptions{GuildID: "guild-1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.MarkMemoAsSent(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		memoID     int32
+		newOwnerID string
+		wantErr    string
+	}{
+		{name: "same owner", memoID: 1, newOwnerID: "user-1", wantErr: "already belongs to <@user-1>"},
+		{name: "delivered memo", memoID: 2, newOwnerID: "user-2", wantErr: "already delivered"},
+		{name: "unknown memo", memoID: 9, newOwnerID: "user-2", wantErr: "not found"},
+		{name: "pending memo", memoID: 1, newOwnerID: "user-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memo, err := svc.ReassignMemo(ctx, tt.memoID, tt.newOwnerID, "mod")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReassignMemo() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReassignMemo() error = %v", err)
+			}
+			if memo.DiscordUserID != tt.newOwnerID {
+				t.Fatalf("owner = %s, want %s", memo.DiscordUserID, tt.newOwnerID)
+			}
+		})
+	}
+
+	log := store.ModerationLog()
+	if len(log) != 1 {
+		t.Fatalf("moderation log = %+v, want only the successful reassignment", log)
+	}
+	if e := log[0]; e.MemoID != 1 || e.Action != ModerationReassign || e.OwnerID != "user-1" || e.NewOwnerID.String != "user-2" || e.GuildID.String != "guild-1" {
+		t.Fatalf("moderation log entry = %+v", e)
+	}
+}
+
+func TestReassignMemoRespectsLimits(t *testing.T) {
+	svc, _, clk := newTestService()
+	ctx := context.Background()
+	create := func(userID, guildID string) {
+		t.Helper()
+		if _, err := svc.CreateMemo(ctx, userID, "channel-1", "water the plants", clk.Now().Add(time.Hour), MemoOptions{GuildID: guildID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	create("user-1", "guild-1") // #1
+	create("user-1", "guild-1") // #2
+	create("user-2", "guild-1") // #3
+	create("user-3", "guild-2") // #4
+
+	// The bot-wide limit counts every server
+	svc.SetLimits(Limits{MaxPendingPerUser: 1})
+	if _, err := svc.ReassignMemo(ctx, 1, "user-3", "mod"); err == nil || !strings.Contains(err.Error(), "<@user-3> already has 1 pending memos, the limit is 1") {
+		t.Fatalf("ReassignMemo() past the bot-wide limit error = %v", err)
+	}
+
+	svc.SetLimits(Limits{})
+	if err := svc.SaveGuildSettings(ctx, db.GuildSetting{GuildID: "guild-1", ListShowOthers: true, MaxPendingPerUser: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ReassignMemo(ctx, 1, "user-2", "mod"); err == nil || !strings.Contains(err.Error(), "<@user-2> already has 1 pending memos in this server, the limit is 1") {
+		t.Fatalf("ReassignMemo() past the server limit error = %v", err)
+	}
+	// user-3's memo elsewhere doesn't count against guild-1's limit
+	if _, err := svc.ReassignMemo(ctx, 1, "user-3", "mod"); err != nil {
+		t.Fatalf("ReassignMemo() within the limits error = %v", err)
+	}
+}
+
+func TestPurgeUserMemos(t *testing.T) {
+	svc, store, clk := newTestService()
+	ctx := context.Background()
+	create := func(userID, guildID string) {
+		t.Helper()
+		if _, err := svc.CreateMemo(ctx, userID, "channel-1", "water the plants", clk.Now().Add(time.Hour), MemoOptions{GuildID: guildID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	create("user-1", "guild-1")
+	create("user-1", "guild-1")
+	create("user-1", "guild-2")
+	create("user-2", "guild-1")
+	// Delivered memos are history, not pending, and are kept
+	if err := svc.MarkMemoAsSent(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := svc.PurgeUserMemos(ctx, "guild-1", "user-1", "mod", nil)
+	if err != nil {
+		t.Fatalf("PurgeUserMemos() error = %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("PurgeUserMemos() = %d, want 1", n)
+	}
+
+	var ids []int32
+	for _, m := range store.Memos() {
+		ids = append(ids, m.ID)
+	}
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 3 || ids[2] != 4 {
+		t.Fatalf("memos left = %v, want [2 3 4]", ids)
+	}
+	if log := store.ModerationLog(); len(log) != 1 || log[0].MemoID != 1 || log[0].Action != ModerationPurge {
+		t.Fatalf("moderation log = %+v, want the purge of #1", log)
+	}
+
+	if _, err := svc.PurgeUserMemos(ctx, "", "user-1", "mod", nil); err == nil {
+		t.Fatal("PurgeUserMemos() outside a server succeeded, want an error")
+	}
+}
+
+func TestModerationIsAtomic(t *testing.T) {
+	svc, store, clk := newTestService()
+	ctx := context.Background()
+	for n := 0; n < 2; n++ {
+		if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "water the plants", clk.Now().Add(time.Hour), MemoOptions{GuildID: "guild-1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	memo, err := svc.GetMemo(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.FailModeration(errors.New("disk full"))
+
+	// A change that can't be logged isn't made
+	if err := svc.DeleteMemoAsModerator(ctx, *memo, "mod"); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("DeleteMemoAsModerator() error = %v, want the log failure", err)
+	}
+	if _, err := svc.ReassignMemo(ctx, 1, "user-2", "mod"); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("ReassignMemo() error = %v, want the log failure", err)
+	}
+	if _, err := svc.PurgeUserMemos(ctx, "guild-1", "user-1", "mod", nil); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("PurgeUserMemos() error = %v, want the log failure", err)
+	}
+	memos := store.Memos()
+	if len(memos) != 2 || memos[0].DiscordUserID != "user-1" || memos[1].DiscordUserID != "user-1" {
+		t.Fatalf("memos = %+v, want both kept by user-1", memos)
+	}
+
+	store.FailModeration(nil)
+	if err := svc.DeleteMemoAsModerator(ctx, *memo, "mod"); err != nil {
+		t.Fatalf("DeleteMemoAsModerator() error = %v", err)
+	}
+	if n := len(store.Memos()); n != 1 {
+		t.Fatalf("%d memos left, want 1", n)
+	}
+	if log := store.ModerationLog(); len(log) != 1 || log[0].MemoID != 1 || log[0].Action != ModerationDelete {
+		t.Fatalf("moderation log = %+v, want only the delete of #1", log)
+	}
+}
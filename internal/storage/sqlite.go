@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"memo-bot/internal/db"
+	"memo-bot/internal/db/sqlite"
+)
+
+// sqliteQuerier adapts the SQLite queries to db.Querier. The generated row types
+// match the Postgres models field for field, so rows convert directly; times are
+// written in UTC so the stored text compares in chronological order.
+type sqliteQuerier struct {
+	q *sqlite.Queries
+	// conn is nil for the querier handed to a transaction
+	conn *sql.DB
+}
+
+var _ db.Querier = (*sqliteQuerier)(nil)
+
+func (s *sqliteQuerier) InTx(ctx context.Context, fn func(q db.Querier) error) error {
+	return inTx(ctx, s.conn, func(tx *sql.Tx) error {
+		return fn(&sqliteQuerier{q: s.q.WithTx(tx)})
+	})
+}
+
+func (s *sqliteQuerier) ClaimPendingReminders(ctx context.Context, arg db.ClaimPendingRemindersParams) ([]db.Memo, error) {
+	return memos(s.q.ClaimPendingReminders(ctx, sqlite.ClaimPendingRemindersParams{
+		WorkerID:   arg.WorkerID,
+		LeaseUntil: utcNull(arg.LeaseUntil),
+		Now:        arg.Now.UTC(),
+		BatchSize:  int64(arg.BatchSize),
+	}))
+}
+
+func (s *sqliteQuerier) CountPendingMemosInChannel(ctx context.Context, discordChannelID string) (int64, error) {
+	return s.q.CountPendingMemosInChannel(ctx, discordChannelID)
+}
+
+func (s *sqliteQuerier) CountUserPendingMemos(ctx context.Context, discordUserID string) (int64, error) {
+	return s.q.CountUserPendingMemos(ctx, discordUserID)
+}
+
+func (s *sqliteQuerier) CountUserPendingMemosInGuild(ctx context.Context, arg db.CountUserPendingMemosInGuildParams) (int64, error) {
+	return s.q.CountUserPendingMemosInGuild(ctx, sqlite.CountUserPendingMemosInGuildParams(arg))
+}
+
+func (s *sqliteQuerier) CreateMemo(ctx context.Context, arg db.CreateMemoParams) (db.Memo, error) {
+	return memo(s.q.CreateMemo(ctx, sqlite.CreateMemoParams{
+		DiscordUserID:    arg.DiscordUserID,
+		DiscordChannelID: arg.DiscordChannelID,
+		Content:          arg.Content,
+		RemindAt:         arg.RemindAt.UTC(),
+		Recurrence:       arg.Recurrence,
+		Delivery:         arg.Delivery,
+		GuildID:          arg.GuildID,
+		SourceMessageID:  arg.SourceMessageID,
+		Mentions:         arg.Mentions,
+	}))
+}
+
+func (s *sqliteQuerier) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	user, err := s.q.CreateUser(ctx, sqlite.CreateUserParams(arg))
+	return db.User(user), err
+}
+
+func (s *sqliteQuerier) DeleteMemo(ctx context.Context, arg db.DeleteMemoParams) (int64, error) {
+	return s.q.DeleteMemo(ctx, sqlite.DeleteMemoParams(arg))
+}
+
+func (s *sqliteQuerier) DeletePendingLegacyMemosByUserInChannel(ctx context.Context, arg db.DeletePendingLegacyMemosByUserInChannelParams) ([]db.Memo, error) {
+	return memos(s.q.DeletePendingLegacyMemosByUserInChannel(ctx, sqlite.DeletePendingLegacyMemosByUserInChannelParams(arg)))
+}
+
+func (s *sqliteQuerier) DeletePendingMemosByUserInGuild(ctx context.Context, arg db.DeletePendingMemosByUserInGuildParams) ([]db.Memo, error) {
+	return memos(s.q.DeletePendingMemosByUserInGuild(ctx, sqlite.DeletePendingMemosByUserInGuildParams(arg)))
+}
+
+func (s *sqliteQuerier) GetGuildSettings(ctx context.Context, guildID string) (db.GuildSetting, error) {
+	settings, err := s.q.GetGuildSettings(ctx, guildID)
+	return db.GuildSetting(settings), err
+}
+
+func (s *sqliteQuerier) GetMemo(ctx context.Context, id int32) (db.Memo, error) {
+	return memo(s.q.GetMemo(ctx, id))
+}
+
+func (s *sqliteQuerier) GetPendingReminders(ctx context.Context, remindAt time.Time) ([]db.Memo, error) {
+	return memos(s.q.GetPendingReminders(ctx, remindAt.UTC()))
+}
+
+func (s *sqliteQuerier) GetReminderCounts(ctx context.Context, discordUserID string) ([]db.GetReminderCountsRow, error) {
+	rows, err := s.q.GetReminderCounts(ctx, discordUserID)
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]db.GetReminderCountsRow, len(rows))
+	for i, row := range rows {
+		counts[i] = db.GetReminderCountsRow(row)
+	}
+	return counts, nil
+}
+
+func (s *sqliteQuerier) GetUser(ctx context.Context, userID string) (db.User, error) {
+	user, err := s.q.GetUser(ctx, userID)
+	return db.User(user), err
+}
+
+func (s *sqliteQuerier) ListAllPendingMemosInChannel(ctx context.Context, discordChannelID string) ([]db.Memo, error) {
+	return memos(s.q.ListAllPendingMemosInChannel(ctx, discordChannelID))
+}
+
+func (s *sqliteQuerier) ListPendingMemos(ctx context.Context, arg db.ListPendingMemosParams) ([]db.Memo, error) {
+	return memos(s.q.ListPendingMemos(ctx, sqlite.ListPendingMemosParams(arg)))
+}
+
+func (s *sqliteQuerier) ListUpcomingReminders(ctx context.Context, limit int32) ([]db.Memo, error) {
+	return memos(s.q.ListUpcomingReminders(ctx, int64(limit)))
+}
+
+func (s *sqliteQuerier) ListUserPendingMemos(ctx context.Context, discordUserID string) ([]db.Memo, error) {
+	return memos(s.q.ListUserPendingMemos(ctx, discordUserID))
+}
+
+func (s *sqliteQuerier) MarkClaimedMemoAsSent(ctx context.Context, arg db.MarkClaimedMemoAsSentParams) (int64, error) {
+	return s.q.MarkClaimedMemoAsSent(ctx, sqlite.MarkClaimedMemoAsSentParams(arg))
+}
+
+func (s *sqliteQuerier) MarkMemoAsSent(ctx context.Context, id int32) error {
+	return s.q.MarkMemoAsSent(ctx, id)
+}
+
+func (s *sqliteQuerier) ReassignMemo(ctx context.Context, arg db.ReassignMemoParams) (db.Memo, error) {
+	return memo(s.q.ReassignMemo(ctx, sqlite.ReassignMemoParams{
+		DiscordUserID: arg.DiscordUserID,
+		ID:            arg.ID,
+	}))
+}
+
+func (s *sqliteQuerier) RecordDeliveryFailure(ctx context.Context, arg db.RecordDeliveryFailureParams) (int64, error) {
+	return s.q.RecordDeliveryFailure(ctx, sqlite.RecordDeliveryFailureParams{
+		LastError:     arg.LastError,
+		NextAttemptAt: utcNull(arg.NextAttemptAt),
+		Failed:        arg.Failed,
+		ID:            arg.ID,
+		ClaimedBy:     arg.ClaimedBy,
+	})
+}
+
+func (s *sqliteQuerier) RecordModeration(ctx context.Context, arg db.RecordModerationParams) error {
+	return s.q.RecordModeration(ctx, sqlite.RecordModerationParams(arg))
+}
+
+func (s *sqliteQuerier) RenewClaim(ctx context.Context, arg db.RenewClaimParams) (int64, error) {
+	return s.q.RenewClaim(ctx, sqlite.RenewClaimParams{
+		ClaimedUntil: utcNull(arg.ClaimedUntil),
+		ID:           arg.ID,
+		ClaimedBy:    arg.ClaimedBy,
+	})
+}
+
+func (s *sqliteQuerier) RescheduleMemo(ctx context.Context, arg db.RescheduleMemoParams) (int64, error) {
+	return s.q.RescheduleMemo(ctx, sqlite.RescheduleMemoParams{
+		RemindAt:  arg.RemindAt.UTC(),
+		ID:        arg.ID,
+		ClaimedBy: arg.ClaimedBy,
+	})
+}
+
+func (s *sqliteQuerier) SearchPendingMemos(ctx context.Context, arg db.SearchPendingMemosParams) ([]db.Memo, error) {
+	return memos(s.q.SearchPendingMemos(ctx, sqlite.SearchPendingMemosParams{
+		DiscordUserID: arg.DiscordUserID,
+		Prefix:        arg.Prefix,
+		MaxResults:    int64(arg.MaxResults),
+	}))
+}
+
+func (s *sqliteQuerier) SetUserTimezone(ctx context.Context, arg db.SetUserTimezoneParams) error {
+	return s.q.SetUserTimezone(ctx, sqlite.SetUserTimezoneParams(arg))
+}
+
+func (s *sqliteQuerier) SnoozeMemo(ctx context.Context, arg db.SnoozeMemoParams) error {
+	return s.q.SnoozeMemo(ctx, sqlite.SnoozeMemoParams{
+		RemindAt: arg.RemindAt.UTC(),
+		ID:       arg.ID,
+	})
+}
+
+func (s *sqliteQuerier) UpdateMemo(ctx context.Context, arg db.UpdateMemoParams) (db.Memo, error) {
+	return memo(s.q.UpdateMemo(ctx, sqlite.UpdateMemoParams{
+		Content:       arg.Content,
+		RemindAt:      arg.RemindAt.UTC(),
+		ID:            arg.ID,
+		DiscordUserID: arg.DiscordUserID,
+	}))
+}
+
+func (s *sqliteQuerier) UpdateUserDiscordChannel(ctx context.Context, arg db.UpdateUserDiscordChannelParams) error {
+	return s.q.UpdateUserDiscordChannel(ctx, sqlite.UpdateUserDiscordChannelParams{
+		DiscordChannelID: arg.DiscordChannelID,
+		UserID:           arg.UserID,
+	})
+}
+
+func (s *sqliteQuerier) UpsertGuildSettings(ctx context.Context, arg db.UpsertGuildSettingsParams) error {
+	return s.q.UpsertGuildSettings(ctx, sqlite.UpsertGuildSettingsParams(arg))
+}
+
+func memo(m sqlite.Memo, err error) (db.Memo, error) {
+	return utcMemo(m), err
+}
+
+func memos(rows []sqlite.Memo, err error) ([]db.Memo, error) {
+	if err != nil {
+		return nil, err
+	}
+	out := make([]db.Memo, len(rows))
+	for i, m := range rows {
+		out[i] = utcMemo(m)
+	}
+	return out, nil
+}
+
+// utcMemo converts a row to the shared model. The driver reads timestamps in
+// the local timezone, so they are converted back to the UTC they were stored in.
+func utcMemo(m sqlite.Memo) db.Memo {
+	m.CreatedAt = utcNull(m.CreatedAt)
+	m.RemindAt = m.RemindAt.UTC()
+	m.ClaimedUntil = utcNull(m.ClaimedUntil)
+	m.NextAttemptAt = utcNull(m.NextAttemptAt)
+	return db.Memo(m)
+}
+
+func utcNull(t sql.NullTime) sql.NullTime {
+	if t.Valid {
+		t.Time = t.Time.UTC()
+	}
+	return t
+}
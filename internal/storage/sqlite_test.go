@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"memo-bot/internal/clock"
+	"memo-bot/internal/config"
+	"memo-bot/internal/db"
+	"memo-bot/internal/service"
+)
+
+func openTestSQLite(t *testing.T) db.Querier {
+	t.Helper()
+	ctx := context.Background()
+	conn, queries, err := Open(ctx, config.DatabaseConfig{Driver: DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := Migrate(ctx, conn, DriverSQLite); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return queries
+}
+
+func TestSQLiteMemoRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	queries := openTestSQLite(t)
+
+	// A non-UTC time must come back as the same instant, in UTC
+	hanoi := time.FixedZone("ICT", 7*60*60)
+	remindAt := time.Date(2099, time.March, 2, 16, 30, 0, 0, hanoi)
+
+	created, err := queries.CreateMemo(ctx, db.CreateMemoParams{
+		DiscordUserID:    "user-1",
+		DiscordChannelID: "channel-1",
+		Content:          "stand-up",
+		RemindAt:         remindAt,
+		Delivery:         "channel",
+		GuildID:          sql.NullString{String: "guild-1", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateMemo() error = %v", err)
+	}
+	if !created.RemindAt.Equal(remindAt) || created.RemindAt.Location() != time.UTC {
+		t.Fatalf("created remind_at = %s, want %s in UTC", created.RemindAt, remindAt.UTC())
+	}
+
+	worker := sql.NullString{String: "worker-1", Valid: true}
+	now := remindAt.Add(time.Minute)
+	leaseUntil := sql.NullTime{Time: now.Add(2 * time.Minute).In(hanoi), Valid: true}
+
+	// Not due yet
+	claimed, err := queries.ClaimPendingReminders(ctx, db.ClaimPendingRemindersParams{
+		WorkerID: worker, LeaseUntil: leaseUntil, Now: remindAt.Add(-time.Second), BatchSize: 10,
+	})
+	if err != nil || len(claimed) != 0 {
+		t.Fatalf("ClaimPendingReminders() before remind_at = %d memos, %v; want none", len(claimed), err)
+	}
+
+	claimed, err = queries.ClaimPendingReminders(ctx, db.ClaimPendingRemindersParams{
+		WorkerID: worker, LeaseUntil: leaseUntil, Now: now, BatchSize: 10,
+	})
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimPendingReminders() = %d memos, %v; want 1", len(claimed), err)
+	}
+	memo := claimed[0]
+	if memo.ID != created.ID || memo.ClaimedBy != worker {
+		t.Fatalf("claimed memo #%d by %q, want #%d by worker-1", memo.ID, memo.ClaimedBy.String, created.ID)
+	}
+	if !memo.RemindAt.Equal(remindAt) || memo.RemindAt.Location() != time.UTC {
+		t.Fatalf("claimed remind_at = %s, want %s in UTC", memo.RemindAt, remindAt.UTC())
+	}
+	if !memo.ClaimedUntil.Time.Equal(leaseUntil.Time) {
+		t.Fatalf("claimed_until = %s, want %s", memo.ClaimedUntil.Time, leaseUntil.Time.UTC())
+	}
+
+	// The lease hides the memo from other workers
+	other, err := queries.ClaimPendingReminders(ctx, db.ClaimPendingRemindersParams{
+		WorkerID: sql.NullString{String: "worker-2", Valid: true}, LeaseUntil: leaseUntil, Now: now, BatchSize: 10,
+	})
+	if err != nil || len(other) != 0 {
+		t.Fatalf("ClaimPendingReminders() by another worker = %d memos, %v; want none", len(other), err)
+	}
+
+	n, err := queries.MarkClaimedMemoAsSent(ctx, db.MarkClaimedMemoAsSentParams{ID: memo.ID, ClaimedBy: worker})
+	if err != nil || n != 1 {
+		t.Fatalf("MarkClaimedMemoAsSent() = %d rows, %v; want 1", n, err)
+	}
+
+	got, err := queries.GetMemo(ctx, memo.ID)
+	if err != nil {
+		t.Fatalf("GetMemo() error = %v", err)
+	}
+	if !got.Sent.Bool || got.ClaimedBy.Valid || got.ClaimedUntil.Valid {
+		t.Fatalf("completed memo sent = %v, claimed by %q until %v; want sent and unclaimed", got.Sent.Bool, got.ClaimedBy.String, got.ClaimedUntil)
+	}
+	if !got.RemindAt.Equal(remindAt) || got.RemindAt.Location() != time.UTC {
+		t.Fatalf("completed remind_at = %s, want %s in UTC", got.RemindAt, remindAt.UTC())
+	}
+}
+
+func TestSQLiteModerationRollsBack(t *testing.T) {
+	ctx := context.Background()
+	queries := openTestSQLite(t)
+	svc := service.NewMemoService(queries, clock.System)
+	if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", "stand-up", time.Now().Add(time.Hour), service.MemoOptions{GuildID: "guild-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting and logging commit together
+	tx := queries.(service.TxQuerier)
+	err := tx.InTx(ctx, func(q db.Querier) error {
+		if n, err := q.DeleteMemo(ctx, db.DeleteMemoParams{ID: 1, DiscordUserID: "user-1"}); err != nil || n != 1 {
+			t.Fatalf("DeleteMemo() in a transaction = %d rows, %v", n, err)
+		}
+		return errors.New("the log insert failed")
+	})
+	if err == nil {
+		t.Fatal("InTx() error = nil, want fn's error")
+	}
+	if _, err := queries.GetMemo(ctx, 1); err != nil {
+		t.Fatalf("GetMemo() after a rolled back delete error = %v, want the memo kept", err)
+	}
+
+	memo, err := svc.GetMemo(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteMemoAsModerator(ctx, *memo, "mod"); err != nil {
+		t.Fatalf("DeleteMemoAsModerator() error = %v", err)
+	}
+	if _, err := queries.GetMemo(ctx, 1); err != sql.ErrNoRows {
+		t.Fatalf("GetMemo() after a moderator delete error = %v, want no rows", err)
+	}
+}
+
+func TestSQLiteSearchEscapesWildcards(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewMemoService(openTestSQLite(t), clock.System)
+	remindAt := time.Now().Add(time.Hour)
+	for _, content := range []string{"100% done", "1000 words", "plan_b", "planb", `C:\temp`, "Cxtemp"} {
+		if _, err := svc.CreateMemo(ctx, "user-1", "channel-1", content, remindAt, service.MemoOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "100%", want: []string{"100% done"}},
+		{prefix: "plan_", want: []string{"plan_b"}},
+		{prefix: `C:\`, want: []string{`C:\temp`}},
+		{prefix: "%", want: nil},
+		{prefix: "_", want: nil},
+		{prefix: "PLAN", want: []string{"plan_b", "planb"}},
+	}
+	for _, tt := range tests {
+		memos, err := svc.SearchPendingMemos(ctx, "user-1", tt.prefix, 25)
+		if err != nil {
+			t.Fatalf("SearchPendingMemos(%q) error = %v", tt.prefix, err)
+		}
+		var got []string
+		for _, m := range memos {
+			got = append(got, m.Content)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("SearchPendingMemos(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"memo-bot/internal/config"
+	"memo-bot/internal/db"
+	"memo-bot/internal/db/sqlite"
+	"memo-bot/internal/logging"
+	"memo-bot/internal/migrate"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// Database drivers selectable with DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Open connects to the configured database and returns the queries for its
+// SQL dialect. The schema is not touched; run Migrate before using the queries.
+// The caller closes the returned connection.
+func Open(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, db.Querier, error) {
+	switch cfg.Driver {
+	case DriverPostgres:
+		return openPostgres(ctx, cfg)
+	case DriverSQLite:
+		return openSQLite(ctx, cfg)
+	default:
+		return nil, nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+func openPostgres(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, db.Querier, error) {
+	conn, err := sql.Open("postgres", cfg.ConnectionString())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return conn, &postgresQuerier{Queries: db.New(conn), conn: conn}, nil
+}
+
+// postgresQuerier adds transactions to the generated Postgres queries
+type postgresQuerier struct {
+	*db.Queries
+	conn *sql.DB
+}
+
+func (p *postgresQuerier) InTx(ctx context.Context, fn func(q db.Querier) error) error {
+	return inTx(ctx, p.conn, func(tx *sql.Tx) error {
+		return fn(p.Queries.WithTx(tx))
+	})
+}
+
+func openSQLite(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, db.Querier, error) {
+	// _time_format=sqlite stores timestamps as sortable text instead of time.Time.String()
+	dsn := fmt.Sprintf("file:%s?_time_format=sqlite&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", cfg.Path)
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database file: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids "database is locked" errors
+	conn.SetMaxOpenConns(1)
+
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to open database file: %w", err)
+	}
+	return conn, &sqliteQuerier{q: sqlite.New(conn), conn: conn}, nil
+}
+
+// inTx runs fn in a transaction on conn, committing it if fn succeeds
+func inTx(ctx context.Context, conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Migrate brings the schema up to date and logs every migration it applied
+func Migrate(ctx context.Context, conn *sql.DB, driver string) error {
+	runner, err := migrate.New(conn, driver)
+	if err != nil {
+		return err
+	}
+	applied, err := runner.Up(ctx)
+	for _, m := range applied {
+		logging.FromContext(ctx).Info("Applied migration", "version", m.Version, "name", m.Name)
+	}
+	return err
+}
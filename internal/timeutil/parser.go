@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"memo-bot/internal/clock"
+
 	"github.com/olebedev/when"
 	"github.com/olebedev/when/rules/common"
 	"github.com/olebedev/when/rules/en"
@@ -20,8 +22,9 @@ func init() {
 	w.Add(common.All...)
 }
 
-// ParseTime converts natural language time expressions into time.Time
-func ParseTime(input string, timezone string) (time.Time, error) {
+// ParseTime converts natural language time expressions into time.Time, relative
+// to the current time of clk in timezone
+func ParseTime(input string, timezone string, clk clock.Clock) (time.Time, error) {
 	// Load timezone from config
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
@@ -29,7 +32,7 @@ func ParseTime(input string, timezone string) (time.Time, error) {
 	}
 
 	// Use current time in configured timezone as base time
-	now := time.Now().In(loc)
+	now := clk.Now().In(loc)
 
 	// Parse the natural language time expression
 	result, err := w.Parse(input, now)
@@ -0,0 +1,66 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"memo-bot/internal/clock"
+)
+
+func TestParseTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "relative duration",
+			now:   time.Date(2026, time.March, 2, 9, 0, 0, 0, newYork),
+			input: "in 2 hours",
+			want:  time.Date(2026, time.March, 2, 11, 0, 0, 0, newYork),
+		},
+		{
+			name:  "tomorrow across spring forward",
+			now:   time.Date(2026, time.March, 7, 12, 0, 0, 0, newYork),
+			input: "tomorrow at 9am",
+			want:  time.Date(2026, time.March, 8, 9, 0, 0, 0, newYork),
+		},
+		{
+			name:  "relative duration across fall back",
+			now:   time.Date(2026, time.November, 1, 0, 30, 0, 0, newYork),
+			input: "in 2 hours",
+			want:  time.Date(2026, time.November, 1, 0, 30, 0, 0, newYork).Add(2 * time.Hour),
+		},
+		{
+			name:    "unparseable",
+			now:     time.Date(2026, time.March, 2, 9, 0, 0, 0, newYork),
+			input:   "whenever",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.input, "America/New_York", clock.NewFake(tt.now))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTime(%q) = %s, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTime(%q) error = %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("ParseTime(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package timeutil
+
+import (
+	"strings"
+	"time"
+)
+
+// commonTimezones is the list offered by timezone autocomplete. Any other valid
+// IANA name is still accepted when typed in full.
+var commonTimezones = []string{
+	"UTC",
+	"Africa/Cairo",
+	"Africa/Johannesburg",
+	"Africa/Lagos",
+	"Africa/Nairobi",
+	"America/Anchorage",
+	"America/Argentina/Buenos_Aires",
+	"America/Bogota",
+	"America/Chicago",
+	"America/Denver",
+	"America/Halifax",
+	"America/Lima",
+	"America/Los_Angeles",
+	"America/Mexico_City",
+	"America/New_York",
+	"America/Phoenix",
+	"America/Santiago",
+	"America/Sao_Paulo",
+	"America/St_Johns",
+	"America/Toronto",
+	"America/Vancouver",
+	"Asia/Bangkok",
+	"Asia/Dhaka",
+	"Asia/Dubai",
+	"Asia/Ho_Chi_Minh",
+	"Asia/Hong_Kong",
+	"Asia/Jakarta",
+	"Asia/Jerusalem",
+	"Asia/Karachi",
+	"Asia/Kathmandu",
+	"Asia/Kolkata",
+	"Asia/Manila",
+	"Asia/Seoul",
+	"Asia/Shanghai",
+	"Asia/Singapore",
+	"Asia/Taipei",
+	"Asia/Tehran",
+	"Asia/Tokyo",
+	"Atlantic/Reykjavik",
+	"Australia/Adelaide",
+	"Australia/Brisbane",
+	"Australia/Melbourne",
+	"Australia/Perth",
+	"Australia/Sydney",
+	"Europe/Amsterdam",
+	"Europe/Athens",
+	"Europe/Berlin",
+	"Europe/Dublin",
+	"Europe/Helsinki",
+	"Europe/Istanbul",
+	"Europe/Kyiv",
+	"Europe/Lisbon",
+	"Europe/London",
+	"Europe/Madrid",
+	"Europe/Moscow",
+	"Europe/Paris",
+	"Europe/Rome",
+	"Europe/Stockholm",
+	"Europe/Warsaw",
+	"Europe/Zurich",
+	"Pacific/Auckland",
+	"Pacific/Honolulu",
+}
+
+// ValidTimezone reports whether name is a loadable IANA timezone
+func ValidTimezone(name string) bool {
+	if name == "" || strings.EqualFold(name, "Local") {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// SearchTimezones returns up to limit timezone names containing query (case-insensitive).
+// An exact valid IANA name that isn't in the common list is returned first.
+func SearchTimezones(query string, limit int) []string {
+	query = strings.TrimSpace(query)
+	lower := strings.ToLower(query)
+
+	var matches []string
+	exact := false
+	for _, name := range commonTimezones {
+		if strings.EqualFold(name, query) {
+			exact = true
+		}
+		if strings.Contains(strings.ToLower(name), lower) {
+			matches = append(matches, name)
+		}
+	}
+
+	if !exact && ValidTimezone(query) {
+		matches = append([]string{query}, matches...)
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}